@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log"
 	"runtime"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,6 +14,8 @@ import (
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/config"
 	"github.com/influxdata/telegraf/internal/models"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/serializers"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 )
 
@@ -141,29 +145,34 @@ func (a *Agent) Run(ctx context.Context) error {
 	return nil
 }
 
-// Test runs the inputs once and prints the output to stdout in line protocol.
-func (a *Agent) Test(ctx context.Context, waitDuration time.Duration) error {
+// Test runs the inputs once and prints the output to stdout using the
+// serializer.  If expected is not nil the metrics are compared to the
+// expected metrics, ignoring timestamps and order, and an error is returned
+// if they differ.
+func (a *Agent) Test(
+	ctx context.Context,
+	waitDuration time.Duration,
+	serializer serializers.Serializer,
+	expected []telegraf.Metric,
+) error {
 	var wg sync.WaitGroup
 	metricC := make(chan telegraf.Metric)
 	nulC := make(chan telegraf.Metric)
-	defer func() {
-		close(metricC)
-		close(nulC)
-		wg.Wait()
-	}()
 
+	var actual []telegraf.Metric
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
 
-		s := influx.NewSerializer()
-		s.SetFieldSortOrder(influx.SortFields)
-		for metric := range metricC {
-			octets, err := s.Serialize(metric)
+		for m := range metricC {
+			octets, err := serializer.Serialize(m)
 			if err == nil {
 				fmt.Print("> ", string(octets))
 			}
-			metric.Reject()
+			if expected != nil {
+				actual = append(actual, metric.FromMetric(m))
+			}
+			m.Reject()
 		}
 	}()
 
@@ -174,6 +183,33 @@ func (a *Agent) Test(ctx context.Context, waitDuration time.Duration) error {
 		}
 	}()
 
+	err := a.testInputs(ctx, waitDuration, metricC, nulC)
+	close(metricC)
+	close(nulC)
+	wg.Wait()
+	if err != nil {
+		return err
+	}
+
+	if expected == nil {
+		return nil
+	}
+
+	if diff := diffMetrics(expected, actual); diff != "" {
+		fmt.Printf("--- expected\n+++ actual\n%s", diff)
+		return fmt.Errorf("metrics differ from expected metrics")
+	}
+	return nil
+}
+
+// testInputs runs the inputs once, sending the metrics to metricC.  Inputs
+// that need to be run twice send the metrics of the first run to nulC.
+func (a *Agent) testInputs(
+	ctx context.Context,
+	waitDuration time.Duration,
+	metricC chan<- telegraf.Metric,
+	nulC chan<- telegraf.Metric,
+) error {
 	hasServiceInputs := false
 	for _, input := range a.Config.Inputs {
 		if _, ok := input.Input.(telegraf.ServiceInput); ok {
@@ -353,6 +389,14 @@ func (a *Agent) runProcessors(
 		}
 	}
 
+	// When there are aggregators the processors are also applied to their
+	// output, so the processors are stopped after the aggregators.
+	if len(a.Config.Aggregators) == 0 {
+		for _, metric := range a.stopProcessors() {
+			agg <- metric
+		}
+	}
+
 	return nil
 }
 
@@ -366,6 +410,20 @@ func (a *Agent) applyProcessors(m telegraf.Metric) []telegraf.Metric {
 	return metrics
 }
 
+// stopProcessors stops all processors.  The metrics returned by each
+// processor are applied to the processors following it.
+func (a *Agent) stopProcessors() []telegraf.Metric {
+	var metrics []telegraf.Metric
+	for _, processor := range a.Config.Processors {
+		if len(metrics) > 0 {
+			metrics = processor.Apply(metrics...)
+		}
+		metrics = append(metrics, processor.Stop()...)
+	}
+
+	return metrics
+}
+
 func updateWindow(start time.Time, roundInterval bool, period time.Duration) (time.Time, time.Time) {
 	var until time.Time
 	if roundInterval {
@@ -451,6 +509,10 @@ func (a *Agent) runAggregators(
 	}
 
 	wg.Wait()
+
+	for _, metric := range a.stopProcessors() {
+		dst <- metric
+	}
 	return nil
 }
 
@@ -749,3 +811,60 @@ func panicRecover(input *models.RunningInput) {
 			"https://github.com/influxdata/telegraf/issues/new/choose")
 	}
 }
+
+// diffMetrics returns the metrics missing from actual prefixed with "- " and
+// the unexpected metrics prefixed with "+ ", or an empty string if the metrics
+// are equal when ignoring timestamps and order.
+func diffMetrics(expected, actual []telegraf.Metric) string {
+	s := influx.NewSerializer()
+	s.SetFieldSortOrder(influx.SortFields)
+	s.SetFieldTypeSupport(influx.UintSupport)
+
+	counts := make(map[string]int)
+	for _, m := range expected {
+		counts[formatMetric(s, m)]++
+	}
+
+	var unexpected []string
+	for _, m := range actual {
+		line := formatMetric(s, m)
+		if counts[line] > 0 {
+			counts[line]--
+			continue
+		}
+		unexpected = append(unexpected, line)
+	}
+
+	var missing []string
+	for line, n := range counts {
+		for ; n > 0; n-- {
+			missing = append(missing, line)
+		}
+	}
+
+	sort.Strings(missing)
+	sort.Strings(unexpected)
+
+	var diff strings.Builder
+	for _, line := range missing {
+		diff.WriteString("- " + line + "\n")
+	}
+	for _, line := range unexpected {
+		diff.WriteString("+ " + line + "\n")
+	}
+	return diff.String()
+}
+
+// formatMetric returns the metric in line protocol without the timestamp.
+func formatMetric(s *influx.Serializer, m telegraf.Metric) string {
+	octets, err := s.Serialize(m)
+	if err != nil {
+		return m.Name() + " " + err.Error()
+	}
+
+	line := strings.TrimSuffix(string(octets), "\n")
+	if i := strings.LastIndexByte(line, ' '); i >= 0 {
+		line = line[:i]
+	}
+	return line
+}
@@ -4,9 +4,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal/config"
 	_ "github.com/influxdata/telegraf/plugins/inputs/all"
 	_ "github.com/influxdata/telegraf/plugins/outputs/all"
+	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -166,3 +168,31 @@ func TestWindow(t *testing.T) {
 		})
 	}
 }
+
+func TestDiffMetrics(t *testing.T) {
+	expected := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"cpu": "cpu0"},
+			map[string]interface{}{"idle": 42.0, "user": 8.0}, time.Unix(0, 0)),
+		testutil.MustMetric("mem", map[string]string{},
+			map[string]interface{}{"used": int64(100)}, time.Unix(0, 0)),
+		testutil.MustMetric("disk", map[string]string{},
+			map[string]interface{}{"free": int64(1)}, time.Unix(0, 0)),
+	}
+
+	actual := []telegraf.Metric{
+		testutil.MustMetric("mem", map[string]string{},
+			map[string]interface{}{"used": int64(100)}, time.Unix(10, 0)),
+		testutil.MustMetric("cpu", map[string]string{"cpu": "cpu0"},
+			map[string]interface{}{"user": 8.0, "idle": 42.0}, time.Unix(10, 0)),
+	}
+	assert.Equal(t, "- disk free=1i\n", diffMetrics(expected, actual))
+
+	actual = append(actual,
+		testutil.MustMetric("disk", map[string]string{},
+			map[string]interface{}{"free": uint64(1)}, time.Unix(10, 0)))
+	assert.Equal(t, "- disk free=1i\n+ disk free=1u\n", diffMetrics(expected, actual))
+
+	actual[2] = expected[2]
+	assert.Equal(t, "", diffMetrics(expected, actual))
+	assert.Equal(t, "", diffMetrics(nil, nil))
+}
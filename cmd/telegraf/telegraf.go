@@ -5,6 +5,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
 	_ "net/http/pprof" // Comment this line to disable pprof endpoint.
@@ -18,6 +19,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/agent"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/config"
@@ -27,7 +29,9 @@ import (
 	_ "github.com/influxdata/telegraf/plugins/inputs/all"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	_ "github.com/influxdata/telegraf/plugins/outputs/all"
+	"github.com/influxdata/telegraf/plugins/parsers"
 	_ "github.com/influxdata/telegraf/plugins/processors/all"
+	"github.com/influxdata/telegraf/plugins/serializers"
 	"github.com/kardianos/service"
 )
 
@@ -39,6 +43,9 @@ var fQuiet = flag.Bool("quiet", false,
 	"run in quiet mode")
 var fTest = flag.Bool("test", false, "enable test mode: gather metrics, print them out, and exit")
 var fTestWait = flag.Int("test-wait", 0, "wait up to this many seconds for service inputs to complete in test mode")
+var fTestFormat = flag.String("test-format", "influx", "data format of the metrics printed in test mode")
+var fTestExpected = flag.String("test-expected", "",
+	"file with the expected metrics in line protocol, test mode fails if the metrics differ")
 var fConfig = flag.String("config", "", "configuration file to load")
 var fConfigDirectory = flag.String("config-directory", "",
 	"directory containing additional *.conf files")
@@ -204,8 +211,25 @@ func runAgent(ctx context.Context,
 	logger.SetupLogging(logConfig)
 
 	if *fTest || *fTestWait != 0 {
+		serializer, err := serializers.NewSerializer(&serializers.Config{
+			DataFormat:       *fTestFormat,
+			InfluxSortFields: true,
+			TimestampUnits:   time.Second,
+		})
+		if err != nil {
+			return err
+		}
+
+		var expected []telegraf.Metric
+		if *fTestExpected != "" {
+			expected, err = loadMetrics(*fTestExpected)
+			if err != nil {
+				return err
+			}
+		}
+
 		testWaitDuration := time.Duration(*fTestWait) * time.Second
-		return ag.Test(ctx, testWaitDuration)
+		return ag.Test(ctx, testWaitDuration, serializer, expected)
 	}
 
 	log.Printf("I! Loaded inputs: %s", strings.Join(c.InputNames(), " "))
@@ -235,6 +259,27 @@ func runAgent(ctx context.Context,
 	return ag.Run(ctx)
 }
 
+// loadMetrics reads the metrics in line protocol from the file.
+func loadMetrics(path string) ([]telegraf.Metric, error) {
+	octets, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	parser, err := parsers.NewInfluxParser()
+	if err != nil {
+		return nil, err
+	}
+
+	metrics, err := parser.Parse(octets)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse metrics in %s: %v", path, err)
+	}
+
+	// An empty file expects no metrics.
+	return append([]telegraf.Metric{}, metrics...), nil
+}
+
 func usageExit(rc int) {
 	fmt.Println(internal.Usage)
 	os.Exit(rc)
@@ -43,3 +43,9 @@ func (h *basicAuthHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request)
 
 	h.next.ServeHTTP(rw, req)
 }
+
+// IsBodyTooLarge returns true if the error was returned by a reader created
+// with http.MaxBytesReader because the request body exceeds the limit.
+func IsBodyTooLarge(err error) bool {
+	return err != nil && err.Error() == "http: request body too large"
+}
@@ -77,3 +77,14 @@ func (rp *RunningProcessor) Apply(in ...telegraf.Metric) []telegraf.Metric {
 
 	return ret
 }
+
+// Stop stops the processor and returns the metrics it was holding.
+func (rp *RunningProcessor) Stop() []telegraf.Metric {
+	rp.Lock()
+	defer rp.Unlock()
+
+	if p, ok := rp.Processor.(telegraf.StoppableProcessor); ok {
+		return p.Stop()
+	}
+	return nil
+}
@@ -31,6 +31,11 @@ The commands & flags are:
                                  'processors', 'aggregators' and 'inputs'
   --test                         gather metrics, print them out, and exit;
                                  processors, aggregators, and outputs are not run
+  --test-expected <file>         file with the expected metrics in line protocol,
+                                 test mode fails if the gathered metrics differ,
+                                 ignoring timestamps and order
+  --test-format <format>         data format of the metrics printed in test mode,
+                                 one of the formats in docs/DATA_FORMATS_OUTPUT.md
   --test-wait                    wait up to this many seconds for service
                                  inputs to complete in test mode
   --usage <plugin>               print usage for a plugin, ie, 'telegraf --usage mysql'
@@ -52,6 +57,12 @@ Examples:
   # run a single telegraf collection, outputing metrics to stdout
   telegraf --config telegraf.conf --test
 
+  # run a single telegraf collection, outputing metrics to stdout as json
+  telegraf --config telegraf.conf --test --test-format json
+
+  # run a single telegraf collection, comparing metrics to expected.out
+  telegraf --config telegraf.conf --test --test-expected expected.out
+
   # run telegraf with all plugins defined in config file
   telegraf --config telegraf.conf
 
@@ -0,0 +1,232 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+type Anomaly struct {
+	Fields      []string          `toml:"fields"`
+	Alpha       float64           `toml:"alpha"`
+	Threshold   float64           `toml:"threshold"`
+	Warmup      int               `toml:"warmup"`
+	ExpireAfter internal.Duration `toml:"expire_after"`
+
+	fieldFilter filter.Filter
+	cache       map[uint64]*series
+}
+
+// series holds the baseline of each field of a series, which survives
+// across periods, and the values added in the current period.
+type series struct {
+	name     string
+	tags     map[string]string
+	fields   map[string]*baseline
+	lastSeen time.Time
+}
+
+// baseline is the exponentially weighted mean and variance of a field.
+type baseline struct {
+	mean     float64
+	variance float64
+	periods  int
+
+	sum   float64
+	count int
+}
+
+func NewAnomaly() *Anomaly {
+	return &Anomaly{
+		Fields:      []string{"*"},
+		Alpha:       0.1,
+		Threshold:   3,
+		Warmup:      5,
+		ExpireAfter: internal.Duration{Duration: 24 * time.Hour},
+		cache:       make(map[uint64]*series),
+	}
+}
+
+var sampleConfig = `
+  ## General Aggregator Arguments:
+  ## The period on which to flush & clear the aggregator.
+  period = "30s"
+  ## If true, the original metric will be dropped by the
+  ## aggregator and will not get sent to the output plugins.
+  drop_original = false
+
+  ## Fields to check for anomalies, accepts glob patterns.  Fields that are
+  ## not numeric are ignored.
+  # fields = ["*"]
+
+  ## Weight of the value of each period in the baseline between 0 and 1,
+  ## higher values adapt to changes faster.
+  # alpha = 0.1
+
+  ## Number of standard deviations from the baseline at which a value is an
+  ## anomaly.
+  # threshold = 3.0
+
+  ## Number of periods used to build the baseline before anomalies are
+  ## reported.
+  # warmup = 5
+
+  ## Forget the baseline of series that have not been seen for this long.
+  # expire_after = "24h"
+`
+
+func (a *Anomaly) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *Anomaly) Description() string {
+	return "Detect anomalies using an exponentially weighted baseline of each field."
+}
+
+func (a *Anomaly) Init() error {
+	if a.Alpha <= 0 || a.Alpha > 1 {
+		return fmt.Errorf("alpha must be greater than 0 and at most 1, got %v", a.Alpha)
+	}
+
+	if a.Threshold <= 0 {
+		return fmt.Errorf("threshold must be positive, got %v", a.Threshold)
+	}
+
+	var err error
+	a.fieldFilter, err = filter.Compile(a.Fields)
+	if err != nil {
+		return fmt.Errorf("could not compile fields: %v", err)
+	}
+	return nil
+}
+
+func (a *Anomaly) Add(in telegraf.Metric) {
+	id := in.HashID()
+	s, ok := a.cache[id]
+	if !ok {
+		s = &series{
+			name:   in.Name(),
+			tags:   in.Tags(),
+			fields: make(map[string]*baseline),
+		}
+		a.cache[id] = s
+	}
+	s.lastSeen = time.Now()
+
+	for _, field := range in.FieldList() {
+		if a.fieldFilter != nil && !a.fieldFilter.Match(field.Key) {
+			continue
+		}
+
+		value, ok := convert(field.Value)
+		if !ok {
+			continue
+		}
+
+		b, ok := s.fields[field.Key]
+		if !ok {
+			b = &baseline{}
+			s.fields[field.Key] = b
+		}
+		b.sum += value
+		b.count++
+	}
+}
+
+func (a *Anomaly) Push(acc telegraf.Accumulator) {
+	now := time.Now()
+	for id, s := range a.cache {
+		if a.ExpireAfter.Duration > 0 && now.Sub(s.lastSeen) >= a.ExpireAfter.Duration {
+			delete(a.cache, id)
+			continue
+		}
+
+		fields := make(map[string]interface{})
+		for key, b := range s.fields {
+			if b.count == 0 {
+				continue
+			}
+			value := b.sum / float64(b.count)
+
+			// The value is compared to the baseline of the previous periods
+			// before it is added to the baseline.
+			if b.periods > 0 {
+				stddev := math.Sqrt(b.variance)
+
+				fields[key+"_baseline"] = b.mean
+				fields[key+"_upper"] = b.mean + a.Threshold*stddev
+				fields[key+"_lower"] = b.mean - a.Threshold*stddev
+
+				// A baseline without variance, such as from a constant
+				// value, has no z-score for other values, any change from
+				// it is an anomaly.
+				var anomaly bool
+				switch {
+				case stddev > 0:
+					zscore := (value - b.mean) / stddev
+					fields[key+"_zscore"] = zscore
+					anomaly = math.Abs(zscore) > a.Threshold
+				case value == b.mean:
+					fields[key+"_zscore"] = 0.0
+				default:
+					anomaly = true
+				}
+				fields[key+"_anomaly"] = b.periods >= a.Warmup && anomaly
+			}
+
+			b.update(value, a.Alpha)
+		}
+
+		if len(fields) > 0 {
+			acc.AddFields(s.name, fields, s.tags)
+		}
+	}
+}
+
+// update adds the value of a period to the exponentially weighted mean and
+// variance.
+func (b *baseline) update(value float64, alpha float64) {
+	if b.periods == 0 {
+		b.mean = value
+	} else {
+		diff := value - b.mean
+		incr := alpha * diff
+		b.mean += incr
+		b.variance = (1 - alpha) * (b.variance + diff*incr)
+	}
+	b.periods++
+}
+
+// Reset clears the values of the period, the baselines are kept.
+func (a *Anomaly) Reset() {
+	for _, s := range a.cache {
+		for _, b := range s.fields {
+			b.sum = 0
+			b.count = 0
+		}
+	}
+}
+
+func convert(in interface{}) (float64, bool) {
+	switch v := in.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func init() {
+	aggregators.Add("anomaly", func() telegraf.Aggregator {
+		return NewAnomaly()
+	})
+}
@@ -0,0 +1,138 @@
+package anomaly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func addPeriod(a *Anomaly, values ...float64) *testutil.Accumulator {
+	for _, v := range values {
+		a.Add(testutil.MustMetric(
+			"cpu",
+			map[string]string{"cpu": "cpu-total"},
+			map[string]interface{}{"usage_idle": v, "state": "ok"},
+			time.Now(),
+		))
+	}
+
+	acc := &testutil.Accumulator{}
+	a.Push(acc)
+	a.Reset()
+	return acc
+}
+
+func TestAnomaly(t *testing.T) {
+	a := NewAnomaly()
+	a.Alpha = 0.5
+	a.Warmup = 3
+	require.NoError(t, a.Init())
+
+	// The first period only initializes the baseline.
+	acc := addPeriod(a, 90, 92)
+	require.Len(t, acc.Metrics, 0)
+
+	acc = addPeriod(a, 93)
+	require.Len(t, acc.Metrics, 1)
+	acc.AssertContainsTaggedFields(t, "cpu",
+		map[string]interface{}{
+			"usage_idle_baseline": 91.0,
+			"usage_idle_upper":    91.0,
+			"usage_idle_lower":    91.0,
+			"usage_idle_anomaly":  false,
+		},
+		map[string]string{"cpu": "cpu-total"})
+
+	for i := 0; i < 5; i++ {
+		acc = addPeriod(a, 90)
+		acc = addPeriod(a, 92)
+	}
+	anomaly, ok := acc.Metrics[0].Fields["usage_idle_anomaly"]
+	require.True(t, ok)
+	require.Equal(t, false, anomaly)
+
+	acc = addPeriod(a, 10)
+	fields := acc.Metrics[0].Fields
+	require.Equal(t, true, fields["usage_idle_anomaly"])
+	require.True(t, fields["usage_idle_zscore"].(float64) < -3)
+	require.True(t, fields["usage_idle_lower"].(float64) > 10)
+	require.True(t, fields["usage_idle_upper"].(float64) < 100)
+}
+
+func TestConstantBaseline(t *testing.T) {
+	a := NewAnomaly()
+	a.Warmup = 3
+	require.NoError(t, a.Init())
+
+	var acc *testutil.Accumulator
+	for i := 0; i < 5; i++ {
+		acc = addPeriod(a, 50)
+	}
+	fields := acc.Metrics[0].Fields
+	require.Equal(t, false, fields["usage_idle_anomaly"])
+	require.Equal(t, 0.0, fields["usage_idle_zscore"])
+
+	acc = addPeriod(a, 1000)
+	fields = acc.Metrics[0].Fields
+	require.Equal(t, true, fields["usage_idle_anomaly"])
+	require.Equal(t, 50.0, fields["usage_idle_baseline"])
+	require.NotContains(t, fields, "usage_idle_zscore")
+}
+
+func TestWarmup(t *testing.T) {
+	a := NewAnomaly()
+	a.Alpha = 0.5
+	a.Warmup = 10
+	require.NoError(t, a.Init())
+
+	addPeriod(a, 90)
+	addPeriod(a, 92)
+	acc := addPeriod(a, 10)
+	require.Equal(t, false, acc.Metrics[0].Fields["usage_idle_anomaly"])
+}
+
+func TestBaselineSurvivesReset(t *testing.T) {
+	a := NewAnomaly()
+	require.NoError(t, a.Init())
+
+	addPeriod(a, 50)
+	a.Reset()
+
+	acc := addPeriod(a, 60)
+	require.Equal(t, 50.0, acc.Metrics[0].Fields["usage_idle_baseline"])
+
+	// Periods without values do not emit or change the baseline.
+	acc = addPeriod(a)
+	require.Len(t, acc.Metrics, 0)
+	acc = addPeriod(a, 60)
+	require.InDelta(t, 51.0, acc.Metrics[0].Fields["usage_idle_baseline"], 1e-9)
+}
+
+func TestExpire(t *testing.T) {
+	a := NewAnomaly()
+	a.ExpireAfter.Duration = time.Minute
+	require.NoError(t, a.Init())
+
+	addPeriod(a, 50)
+	for _, s := range a.cache {
+		s.lastSeen = time.Now().Add(-time.Hour)
+	}
+	addPeriod(a)
+	require.Len(t, a.cache, 0)
+}
+
+func TestInit(t *testing.T) {
+	a := NewAnomaly()
+	a.Alpha = 0
+	require.Error(t, a.Init())
+
+	a = NewAnomaly()
+	a.Alpha = 1.5
+	require.Error(t, a.Init())
+
+	a = NewAnomaly()
+	a.Threshold = 0
+	require.Error(t, a.Init())
+}
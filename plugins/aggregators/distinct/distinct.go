@@ -0,0 +1,179 @@
+package distinct
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+type Distinct struct {
+	Fields     []string `toml:"fields"`
+	Tags       []string `toml:"tags"`
+	Precision  uint8    `toml:"precision"`
+	Cumulative bool     `toml:"cumulative"`
+
+	cache map[uint64]aggregate
+}
+
+type aggregate struct {
+	name     string
+	tags     map[string]string
+	counters map[string]*hyperLogLog
+}
+
+func NewDistinct() *Distinct {
+	d := &Distinct{
+		Precision: 14,
+	}
+	d.cache = make(map[uint64]aggregate)
+	return d
+}
+
+var sampleConfig = `
+  ## General Aggregator Arguments:
+  ## The period on which to flush & clear the aggregator.
+  period = "30s"
+  ## If true, the original metric will be dropped by the
+  ## aggregator and will not get sent to the output plugins.
+  drop_original = false
+
+  ## Fields to count the distinct values of.
+  fields = []
+
+  ## Tags to count the distinct values of, these tags are removed from the
+  ## series of the aggregate.
+  # tags = []
+
+  ## Precision of the estimate between 4 and 18, each counter uses 2^precision
+  ## bytes of memory and has a standard error of about 1.04/sqrt(2^precision).
+  # precision = 14
+
+  ## Count distinct values across all periods instead of resetting the
+  ## counters after each period.
+  # cumulative = false
+`
+
+func (d *Distinct) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *Distinct) Description() string {
+	return "Estimate the number of distinct values of fields and tags."
+}
+
+func (d *Distinct) Init() error {
+	if d.Precision < 4 || d.Precision > 18 {
+		return fmt.Errorf("precision must be between 4 and 18, got %d", d.Precision)
+	}
+
+	if len(d.Fields) == 0 && len(d.Tags) == 0 {
+		return fmt.Errorf("no fields or tags to count")
+	}
+	return nil
+}
+
+func (d *Distinct) Add(in telegraf.Metric) {
+	id, tags := d.series(in)
+	a, ok := d.cache[id]
+	if !ok {
+		a = aggregate{
+			name:     in.Name(),
+			tags:     tags,
+			counters: make(map[string]*hyperLogLog),
+		}
+		d.cache[id] = a
+	}
+
+	for _, key := range d.Tags {
+		if value, ok := in.GetTag(key); ok {
+			d.counter(a, key).add([]byte(value))
+		}
+	}
+
+	for _, key := range d.Fields {
+		if value, ok := in.GetField(key); ok {
+			d.counter(a, key).add([]byte(format(value)))
+		}
+	}
+}
+
+func (d *Distinct) counter(a aggregate, key string) *hyperLogLog {
+	c, ok := a.counters[key]
+	if !ok {
+		c = newHyperLogLog(d.Precision)
+		a.counters[key] = c
+	}
+	return c
+}
+
+// series returns the id and tags of the series of the aggregate, which is the
+// series of the metric without the counted tags.
+func (d *Distinct) series(in telegraf.Metric) (uint64, map[string]string) {
+	tags := in.Tags()
+	for _, key := range d.Tags {
+		delete(tags, key)
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := fnv.New64a()
+	h.Write([]byte(in.Name()))
+	h.Write([]byte("\n"))
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write([]byte("\n"))
+		h.Write([]byte(tags[k]))
+		h.Write([]byte("\n"))
+	}
+	return h.Sum64(), tags
+}
+
+func (d *Distinct) Push(acc telegraf.Accumulator) {
+	for _, a := range d.cache {
+		fields := make(map[string]interface{}, len(a.counters))
+		for key, c := range a.counters {
+			fields[key+"_distinct"] = int64(c.count())
+		}
+		if len(fields) > 0 {
+			acc.AddFields(a.name, fields, a.tags)
+		}
+	}
+}
+
+func (d *Distinct) Reset() {
+	if d.Cumulative {
+		return
+	}
+	d.cache = make(map[uint64]aggregate)
+}
+
+func format(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func init() {
+	aggregators.Add("distinct", func() telegraf.Aggregator {
+		return NewDistinct()
+	})
+}
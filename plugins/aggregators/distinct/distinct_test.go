@@ -0,0 +1,121 @@
+package distinct
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	for _, n := range []int{0, 1, 10, 1000, 100000} {
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			h := newHyperLogLog(14)
+			for i := 0; i < n; i++ {
+				h.add([]byte(fmt.Sprintf("192.0.%d.%d", i/256, i%256)))
+				// Duplicates do not change the estimate.
+				h.add([]byte(fmt.Sprintf("192.0.%d.%d", i/256, i%256)))
+			}
+			require.InEpsilon(t, float64(n)+1, float64(h.count())+1, 0.03)
+		})
+	}
+}
+
+func TestDistinctFields(t *testing.T) {
+	d := NewDistinct()
+	d.Fields = []string{"client_ip"}
+	require.NoError(t, d.Init())
+
+	for i := 0; i < 100; i++ {
+		d.Add(testutil.MustMetric(
+			"nginx",
+			map[string]string{"host": "server01"},
+			map[string]interface{}{
+				"client_ip": fmt.Sprintf("192.0.2.%d", i%20),
+				"bytes":     int64(i),
+			},
+			time.Now(),
+		))
+	}
+
+	acc := testutil.Accumulator{}
+	d.Push(&acc)
+
+	acc.AssertContainsTaggedFields(t, "nginx",
+		map[string]interface{}{"client_ip_distinct": int64(20)},
+		map[string]string{"host": "server01"})
+}
+
+func TestDistinctTags(t *testing.T) {
+	d := NewDistinct()
+	d.Tags = []string{"container_name"}
+	require.NoError(t, d.Init())
+
+	for i := 0; i < 30; i++ {
+		d.Add(testutil.MustMetric(
+			"docker_container_cpu",
+			map[string]string{
+				"host":           "node1",
+				"container_name": fmt.Sprintf("web-%d", i%10),
+			},
+			map[string]interface{}{"usage_percent": 1.0},
+			time.Now(),
+		))
+	}
+
+	acc := testutil.Accumulator{}
+	d.Push(&acc)
+
+	require.Len(t, acc.Metrics, 1)
+	acc.AssertContainsTaggedFields(t, "docker_container_cpu",
+		map[string]interface{}{"container_name_distinct": int64(10)},
+		map[string]string{"host": "node1"})
+}
+
+func TestReset(t *testing.T) {
+	d := NewDistinct()
+	d.Fields = []string{"user"}
+	require.NoError(t, d.Init())
+
+	d.Add(testutil.MustMetric("events", map[string]string{},
+		map[string]interface{}{"user": "alice"}, time.Now()))
+	d.Reset()
+	d.Add(testutil.MustMetric("events", map[string]string{},
+		map[string]interface{}{"user": "bob"}, time.Now()))
+
+	acc := testutil.Accumulator{}
+	d.Push(&acc)
+	acc.AssertContainsFields(t, "events", map[string]interface{}{"user_distinct": int64(1)})
+}
+
+func TestCumulative(t *testing.T) {
+	d := NewDistinct()
+	d.Fields = []string{"user"}
+	d.Cumulative = true
+	require.NoError(t, d.Init())
+
+	d.Add(testutil.MustMetric("events", map[string]string{},
+		map[string]interface{}{"user": "alice"}, time.Now()))
+	d.Reset()
+	d.Add(testutil.MustMetric("events", map[string]string{},
+		map[string]interface{}{"user": "bob"}, time.Now()))
+
+	acc := testutil.Accumulator{}
+	d.Push(&acc)
+	acc.AssertContainsFields(t, "events", map[string]interface{}{"user_distinct": int64(2)})
+}
+
+func TestInit(t *testing.T) {
+	d := NewDistinct()
+	require.Error(t, d.Init())
+
+	d = NewDistinct()
+	d.Fields = []string{"user"}
+	d.Precision = 3
+	require.Error(t, d.Init())
+
+	d.Precision = 19
+	require.Error(t, d.Init())
+}
@@ -0,0 +1,87 @@
+package distinct
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+// hyperLogLog estimates the number of distinct values added to it using a
+// fixed amount of memory of 2^precision bytes.
+//
+// See "HyperLogLog: the analysis of a near-optimal cardinality estimation
+// algorithm" by Flajolet et al.
+type hyperLogLog struct {
+	precision uint8
+	registers []uint8
+}
+
+func newHyperLogLog(precision uint8) *hyperLogLog {
+	return &hyperLogLog{
+		precision: precision,
+		registers: make([]uint8, 1<<precision),
+	}
+}
+
+// add adds a value to the set.
+func (h *hyperLogLog) add(value []byte) {
+	x := hash(value)
+	index := x >> (64 - h.precision)
+
+	// The rank is the position of the first set bit in the remaining bits.
+	w := x<<h.precision | 1<<(h.precision-1)
+	rank := uint8(bits.LeadingZeros64(w)) + 1
+	if rank > h.registers[index] {
+		h.registers[index] = rank
+	}
+}
+
+// count returns the estimated number of distinct values.
+func (h *hyperLogLog) count() uint64 {
+	m := float64(len(h.registers))
+
+	sum := 0.0
+	zeros := 0
+	for _, r := range h.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	estimate := alpha(m) * m * m / sum
+
+	// Small cardinalities are estimated more accurately by linear counting.
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+func alpha(m float64) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	default:
+		return 0.7213 / (1 + 1.079/m)
+	}
+}
+
+// hash returns a 64 bit hash of the value, the FNV-1a hash is mixed with the
+// splitmix64 finalizer to spread the bits of similar values.
+func hash(value []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(value)
+	x := h.Sum64()
+
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,173 @@
+package gapfill
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+var sampleConfig = `
+  ## The period on which to flush & clear the aggregator.
+  period = "30s"
+  ## If true, the original metric will be dropped by the
+  ## aggregator and will not get sent to the output plugins.
+  drop_original = false
+
+  ## The time a series is remembered after it was last seen, no filler
+  ## metrics are emitted for the series afterwards.
+  series_lifetime = "1h"
+
+  ## Fields of the filler metrics:
+  ##   zero - the numeric fields of the last metric set to zero
+  ##   last - the fields of the last metric
+  ##   null - only the null_field field, set to true
+  # fill = "zero"
+
+  ## Name of the field used by the null fill.
+  # null_field = "no_data"
+
+  ## Tag added to the filler metrics, the tag value is "true".
+  # synthetic_tag = "synthetic"
+`
+
+const (
+	fillZero = "zero"
+	fillLast = "last"
+	fillNull = "null"
+)
+
+type GapFill struct {
+	SeriesLifetime internal.Duration `toml:"series_lifetime"`
+	Fill           string            `toml:"fill"`
+	NullField      string            `toml:"null_field"`
+	SyntheticTag   string            `toml:"synthetic_tag"`
+
+	cache map[uint64]*series
+}
+
+// series holds the last metric of a series.
+type series struct {
+	name     string
+	tags     map[string]string
+	fields   map[string]interface{}
+	lastSeen time.Time
+	seen     bool
+}
+
+func NewGapFill() *GapFill {
+	return &GapFill{
+		SeriesLifetime: internal.Duration{Duration: time.Hour},
+		Fill:           fillZero,
+		NullField:      "no_data",
+		SyntheticTag:   "synthetic",
+		cache:          make(map[uint64]*series),
+	}
+}
+
+func (g *GapFill) SampleConfig() string {
+	return sampleConfig
+}
+
+func (g *GapFill) Description() string {
+	return "Emit filler metrics for periods in which a series had no metrics."
+}
+
+func (g *GapFill) Init() error {
+	switch g.Fill {
+	case fillZero, fillLast:
+	case fillNull:
+		if g.NullField == "" {
+			return fmt.Errorf("null_field is required for the null fill")
+		}
+	default:
+		return fmt.Errorf("unknown fill %q", g.Fill)
+	}
+	return nil
+}
+
+func (g *GapFill) Add(in telegraf.Metric) {
+	id := in.HashID()
+	s, ok := g.cache[id]
+	if !ok {
+		s = &series{
+			name: in.Name(),
+			tags: in.Tags(),
+		}
+		g.cache[id] = s
+	}
+	s.fields = in.Fields()
+	s.lastSeen = time.Now()
+	s.seen = true
+}
+
+func (g *GapFill) Push(acc telegraf.Accumulator) {
+	now := time.Now()
+	for id, s := range g.cache {
+		if s.seen {
+			continue
+		}
+
+		if now.Sub(s.lastSeen) > g.SeriesLifetime.Duration {
+			delete(g.cache, id)
+			continue
+		}
+
+		fields := g.fill(s.fields)
+		if len(fields) == 0 {
+			continue
+		}
+
+		tags := make(map[string]string, len(s.tags)+1)
+		for k, v := range s.tags {
+			tags[k] = v
+		}
+		if g.SyntheticTag != "" {
+			tags[g.SyntheticTag] = "true"
+		}
+		acc.AddFields(s.name, fields, tags, now)
+	}
+}
+
+// fill returns the fields of a filler metric for a series with the last
+// fields.
+func (g *GapFill) fill(last map[string]interface{}) map[string]interface{} {
+	switch g.Fill {
+	case fillLast:
+		fields := make(map[string]interface{}, len(last))
+		for k, v := range last {
+			fields[k] = v
+		}
+		return fields
+	case fillNull:
+		return map[string]interface{}{g.NullField: true}
+	default:
+		fields := make(map[string]interface{}, len(last))
+		for k, v := range last {
+			switch v.(type) {
+			case int64:
+				fields[k] = int64(0)
+			case uint64:
+				fields[k] = uint64(0)
+			case float64:
+				fields[k] = float64(0)
+			}
+		}
+		return fields
+	}
+}
+
+// Reset marks all series as not seen in the next period.
+func (g *GapFill) Reset() {
+	for _, s := range g.cache {
+		s.seen = false
+	}
+}
+
+func init() {
+	aggregators.Add("gapfill", func() telegraf.Aggregator {
+		return NewGapFill()
+	})
+}
@@ -0,0 +1,117 @@
+package gapfill
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+var m1 = testutil.MustMetric(
+	"snmp",
+	map[string]string{"device": "switch1"},
+	map[string]interface{}{
+		"in_octets":  int64(100),
+		"out_octets": uint64(200),
+		"load":       0.5,
+		"status":     "up",
+	},
+	time.Now(),
+)
+
+func push(g *GapFill) *testutil.Accumulator {
+	acc := &testutil.Accumulator{}
+	g.Push(acc)
+	g.Reset()
+	return acc
+}
+
+func TestFillZero(t *testing.T) {
+	g := NewGapFill()
+	require.NoError(t, g.Init())
+
+	g.Add(m1)
+	acc := push(g)
+	require.Len(t, acc.Metrics, 0)
+
+	acc = push(g)
+	require.Len(t, acc.Metrics, 1)
+	acc.AssertContainsTaggedFields(t, "snmp",
+		map[string]interface{}{
+			"in_octets":  int64(0),
+			"out_octets": uint64(0),
+			"load":       0.0,
+		},
+		map[string]string{"device": "switch1", "synthetic": "true"})
+
+	// The series is filled in every period until it is seen again.
+	acc = push(g)
+	require.Len(t, acc.Metrics, 1)
+
+	g.Add(m1)
+	acc = push(g)
+	require.Len(t, acc.Metrics, 0)
+}
+
+func TestFillLast(t *testing.T) {
+	g := NewGapFill()
+	g.Fill = "last"
+	g.SyntheticTag = "filled"
+	require.NoError(t, g.Init())
+
+	g.Add(m1)
+	push(g)
+	acc := push(g)
+
+	acc.AssertContainsTaggedFields(t, "snmp",
+		map[string]interface{}{
+			"in_octets":  int64(100),
+			"out_octets": uint64(200),
+			"load":       0.5,
+			"status":     "up",
+		},
+		map[string]string{"device": "switch1", "filled": "true"})
+}
+
+func TestFillNull(t *testing.T) {
+	g := NewGapFill()
+	g.Fill = "null"
+	g.SyntheticTag = ""
+	require.NoError(t, g.Init())
+
+	g.Add(m1)
+	push(g)
+	acc := push(g)
+
+	acc.AssertContainsTaggedFields(t, "snmp",
+		map[string]interface{}{"no_data": true},
+		map[string]string{"device": "switch1"})
+}
+
+func TestSeriesLifetime(t *testing.T) {
+	g := NewGapFill()
+	g.SeriesLifetime.Duration = time.Minute
+	require.NoError(t, g.Init())
+
+	g.Add(m1)
+	push(g)
+
+	for _, s := range g.cache {
+		s.lastSeen = time.Now().Add(-time.Hour)
+	}
+	acc := push(g)
+	require.Len(t, acc.Metrics, 0)
+	require.Len(t, g.cache, 0)
+}
+
+func TestInit(t *testing.T) {
+	g := NewGapFill()
+	g.Fill = "previous"
+	require.Error(t, g.Init())
+
+	g = NewGapFill()
+	g.Fill = "null"
+	g.NullField = ""
+	require.Error(t, g.Init())
+}
@@ -1,6 +1,8 @@
 package histogram
 
 import (
+	"fmt"
+	"math"
 	"sort"
 	"strconv"
 
@@ -11,13 +13,28 @@ import (
 // bucketTag is the tag, which contains right bucket border
 const bucketTag = "le"
 
+// bucketLowerTag is the tag, which contains left bucket border of non-cumulative buckets
+const bucketLowerTag = "gt"
+
 // bucketInf is the right bucket border for infinite values
 const bucketInf = "+Inf"
 
+// bucketNegInf is the left bucket border of the first bucket
+const bucketNegInf = "-Inf"
+
+// bucket types for generated buckets
+const (
+	bucketTypeLinear      = "linear"
+	bucketTypeExponential = "exponential"
+)
+
 // HistogramAggregator is aggregator with histogram configs and particular histograms for defined metrics
 type HistogramAggregator struct {
-	Configs      []config `toml:"config"`
-	ResetBuckets bool     `toml:"reset"`
+	Configs       []config `toml:"config"`
+	ResetBuckets  bool     `toml:"reset"`
+	Cumulative    bool     `toml:"cumulative"`
+	SumCount      bool     `toml:"sum_count"`
+	HistogramType bool     `toml:"histogram_type"`
 
 	buckets bucketsByMetrics
 	cache   map[uint64]metricHistogramCollection
@@ -25,9 +42,14 @@ type HistogramAggregator struct {
 
 // config is the config, which contains name, field of metric and histogram buckets.
 type config struct {
-	Metric  string   `toml:"measurement_name"`
-	Fields  []string `toml:"fields"`
-	Buckets buckets  `toml:"buckets"`
+	Metric       string   `toml:"measurement_name"`
+	Fields       []string `toml:"fields"`
+	Buckets      buckets  `toml:"buckets"`
+	BucketType   string   `toml:"bucket_type"`
+	BucketStart  float64  `toml:"bucket_start"`
+	BucketWidth  float64  `toml:"bucket_width"`
+	BucketFactor float64  `toml:"bucket_factor"`
+	BucketCount  int      `toml:"bucket_count"`
 }
 
 // bucketsByMetrics contains the buckets grouped by metric and field name
@@ -42,6 +64,7 @@ type buckets []float64
 // metricHistogramCollection aggregates the histogram data
 type metricHistogramCollection struct {
 	histogramCollection map[string]counts
+	sums                map[string]float64
 	name                string
 	tags                map[string]string
 }
@@ -58,7 +81,7 @@ type groupedByCountFields struct {
 
 // NewHistogramAggregator creates new histogram aggregator
 func NewHistogramAggregator() telegraf.Aggregator {
-	h := &HistogramAggregator{}
+	h := &HistogramAggregator{Cumulative: true}
 	h.buckets = make(bucketsByMetrics)
 	h.resetCache()
 
@@ -77,6 +100,20 @@ var sampleConfig = `
   ## of accumulating the results.
   reset = false
 
+  ## If false, each bucket contains only the counts of its own range, the
+  ## lower border of the range is added as the "gt" tag.
+  # cumulative = true
+
+  ## If true, the sum and count of the values of each field are added as
+  ## the "<field>_sum" and "<field>_count" fields.
+  # sum_count = false
+
+  ## If true, a histogram metric named "<measurement>_<field>" is emitted for
+  ## each field instead, containing a field for each bucket border and the
+  ## "sum" and "count" fields.  Outputs such as prometheus_client expose
+  ## these metrics as histograms.  The buckets are always cumulative.
+  # histogram_type = false
+
   ## Example config that aggregates all fields of the metric.
   # [[aggregators.histogram.config]]
   #   ## The set of buckets.
@@ -92,6 +129,22 @@ var sampleConfig = `
   #   measurement_name = "diskio"
   #   ## The concrete fields of metric
   #   fields = ["io_time", "read_time", "write_time"]
+
+  ## Example config that generates the buckets.
+  # [[aggregators.histogram.config]]
+  #   ## The type of the generated buckets, "linear" buckets have the
+  #   ## borders start + i * width, "exponential" buckets have the borders
+  #   ## start * factor^i.
+  #   bucket_type = "exponential"
+  #   bucket_start = 0.005
+  #   # bucket_width = 0.1
+  #   bucket_factor = 2.0
+  #   ## The number of buckets, not including the +Inf bucket.
+  #   bucket_count = 10
+  #   ## The name of metric.
+  #   measurement_name = "http_response"
+  #   ## The concrete fields of metric
+  #   fields = ["response_time"]
 `
 
 // SampleConfig returns sample of config
@@ -104,6 +157,58 @@ func (h *HistogramAggregator) Description() string {
 	return "Create aggregate histograms."
 }
 
+// Init generates the buckets of configs with a bucket type
+func (h *HistogramAggregator) Init() error {
+	for i := range h.Configs {
+		cfg := &h.Configs[i]
+		if cfg.BucketType == "" {
+			continue
+		}
+
+		if len(cfg.Buckets) > 0 {
+			return fmt.Errorf("buckets and bucket_type cannot both be set for measurement %q", cfg.Metric)
+		}
+
+		buckets, err := generateBuckets(cfg)
+		if err != nil {
+			return fmt.Errorf("invalid buckets for measurement %q: %v", cfg.Metric, err)
+		}
+		cfg.Buckets = buckets
+	}
+	return nil
+}
+
+// generateBuckets creates the linear or exponential buckets of the config
+func generateBuckets(cfg *config) (buckets, error) {
+	if cfg.BucketCount < 1 {
+		return nil, fmt.Errorf("bucket_count must be positive")
+	}
+
+	result := make(buckets, cfg.BucketCount)
+	switch cfg.BucketType {
+	case bucketTypeLinear:
+		if cfg.BucketWidth <= 0 {
+			return nil, fmt.Errorf("bucket_width must be positive")
+		}
+		for i := range result {
+			result[i] = cfg.BucketStart + float64(i)*cfg.BucketWidth
+		}
+	case bucketTypeExponential:
+		if cfg.BucketStart <= 0 {
+			return nil, fmt.Errorf("bucket_start must be positive")
+		}
+		if cfg.BucketFactor <= 1 {
+			return nil, fmt.Errorf("bucket_factor must be greater than 1")
+		}
+		for i := range result {
+			result[i] = cfg.BucketStart * math.Pow(cfg.BucketFactor, float64(i))
+		}
+	default:
+		return nil, fmt.Errorf("unknown bucket_type %q", cfg.BucketType)
+	}
+	return result, nil
+}
+
 // Add adds new hit to the buckets
 func (h *HistogramAggregator) Add(in telegraf.Metric) {
 	bucketsByField := make(map[string][]float64)
@@ -125,6 +230,7 @@ func (h *HistogramAggregator) Add(in telegraf.Metric) {
 			name:                in.Name(),
 			tags:                in.Tags(),
 			histogramCollection: make(map[string]counts),
+			sums:                make(map[string]float64),
 		}
 	}
 
@@ -137,6 +243,7 @@ func (h *HistogramAggregator) Add(in telegraf.Metric) {
 			if value, ok := convert(value); ok {
 				index := sort.SearchFloat64s(buckets, value)
 				agr.histogramCollection[field][index]++
+				agr.sums[field] += value
 			}
 		}
 	}
@@ -146,12 +253,21 @@ func (h *HistogramAggregator) Add(in telegraf.Metric) {
 
 // Push returns histogram values for metrics
 func (h *HistogramAggregator) Push(acc telegraf.Accumulator) {
+	if h.HistogramType {
+		h.pushHistograms(acc)
+		return
+	}
+
 	metricsWithGroupedFields := []groupedByCountFields{}
 
 	for _, aggregate := range h.cache {
 		for field, counts := range aggregate.histogramCollection {
 			h.groupFieldsByBuckets(&metricsWithGroupedFields, aggregate.name, field, copyTags(aggregate.tags), counts)
 		}
+
+		if h.SumCount {
+			acc.AddFields(aggregate.name, makeSumCountFields(aggregate), copyTags(aggregate.tags))
+		}
 	}
 
 	for _, metric := range metricsWithGroupedFields {
@@ -159,6 +275,26 @@ func (h *HistogramAggregator) Push(acc telegraf.Accumulator) {
 	}
 }
 
+// pushHistograms adds a histogram metric for each field
+func (h *HistogramAggregator) pushHistograms(acc telegraf.Accumulator) {
+	for _, aggregate := range h.cache {
+		for field, counts := range aggregate.histogramCollection {
+			fields := make(map[string]interface{}, len(counts)+1)
+
+			count := int64(0)
+			for index, bucket := range h.getBuckets(aggregate.name, field) {
+				count += counts[index]
+				fields[strconv.FormatFloat(bucket, 'f', -1, 64)] = count
+			}
+			count += counts[len(counts)-1]
+
+			fields["count"] = count
+			fields["sum"] = aggregate.sums[field]
+			acc.AddHistogram(aggregate.name+"_"+field, fields, copyTags(aggregate.tags))
+		}
+	}
+}
+
 // groupFieldsByBuckets groups fields by metric buckets which are represented as tags
 func (h *HistogramAggregator) groupFieldsByBuckets(
 	metricsWithGroupedFields *[]groupedByCountFields,
@@ -168,14 +304,27 @@ func (h *HistogramAggregator) groupFieldsByBuckets(
 	counts []int64,
 ) {
 	count := int64(0)
+	lower := bucketNegInf
 	for index, bucket := range h.getBuckets(name, field) {
-		count += counts[index]
+		border := strconv.FormatFloat(bucket, 'f', -1, 64)
+		if h.Cumulative {
+			count += counts[index]
+		} else {
+			count = counts[index]
+			tags[bucketLowerTag] = lower
+			lower = border
+		}
 
-		tags[bucketTag] = strconv.FormatFloat(bucket, 'f', -1, 64)
+		tags[bucketTag] = border
 		h.groupField(metricsWithGroupedFields, name, field, count, copyTags(tags))
 	}
 
-	count += counts[len(counts)-1]
+	if h.Cumulative {
+		count += counts[len(counts)-1]
+	} else {
+		count = counts[len(counts)-1]
+		tags[bucketLowerTag] = lower
+	}
 	tags[bucketTag] = bucketInf
 
 	h.groupField(metricsWithGroupedFields, name, field, count, tags)
@@ -318,6 +467,21 @@ func makeFieldsWithCount(fieldsWithCountIn map[string]int64) map[string]interfac
 	return fieldsWithCountOut
 }
 
+// makeSumCountFields creates the sum and count fields of all metric fields
+func makeSumCountFields(aggregate metricHistogramCollection) map[string]interface{} {
+	fields := map[string]interface{}{}
+	for field, counts := range aggregate.histogramCollection {
+		count := int64(0)
+		for _, c := range counts {
+			count += c
+		}
+		fields[field+"_sum"] = aggregate.sums[field]
+		fields[field+"_count"] = count
+	}
+
+	return fields
+}
+
 // init initializes histogram aggregator plugin
 func init() {
 	aggregators.Add("histogram", func() telegraf.Aggregator {
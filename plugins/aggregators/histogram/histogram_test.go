@@ -9,11 +9,12 @@ import (
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // NewTestHistogram creates new test histogram aggregation with specified config
 func NewTestHistogram(cfg []config, reset bool) telegraf.Aggregator {
-	htm := &HistogramAggregator{Configs: cfg, ResetBuckets: reset}
+	htm := &HistogramAggregator{Configs: cfg, ResetBuckets: reset, Cumulative: true}
 	htm.buckets = make(bucketsByMetrics)
 	htm.resetCache()
 
@@ -177,6 +178,125 @@ func TestHistogramDifferentPeriodsAndAllFields(t *testing.T) {
 	assertContainsTaggedField(t, acc, "first_metric_name", map[string]interface{}{"a_bucket": int64(2), "b_bucket": int64(1), "c_bucket": int64(1)}, bucketInf)
 }
 
+// TestHistogramNonCumulative tests the counts of non-cumulative buckets
+func TestHistogramNonCumulative(t *testing.T) {
+	var cfg []config
+	cfg = append(cfg, config{Metric: "first_metric_name", Fields: []string{"a"}, Buckets: []float64{0.0, 10.0, 20.0, 30.0, 40.0}})
+	histogram := NewTestHistogram(cfg, false).(*HistogramAggregator)
+	histogram.Cumulative = false
+
+	acc := &testutil.Accumulator{}
+
+	histogram.Add(firstMetric1)
+	histogram.Add(firstMetric2)
+	histogram.Push(acc)
+
+	if len(acc.Metrics) != 6 {
+		assert.Fail(t, "Incorrect number of metrics")
+	}
+	assertContainsTaggedField(t, acc, "first_metric_name", map[string]interface{}{"a_bucket": int64(0)}, "0")
+	assertContainsTaggedField(t, acc, "first_metric_name", map[string]interface{}{"a_bucket": int64(0)}, "10")
+	assertContainsTaggedField(t, acc, "first_metric_name", map[string]interface{}{"a_bucket": int64(2)}, "20")
+	assertContainsTaggedField(t, acc, "first_metric_name", map[string]interface{}{"a_bucket": int64(0)}, "30")
+	assertContainsTaggedField(t, acc, "first_metric_name", map[string]interface{}{"a_bucket": int64(0)}, "40")
+	assertContainsTaggedField(t, acc, "first_metric_name", map[string]interface{}{"a_bucket": int64(0)}, bucketInf)
+
+	acc.AssertContainsTaggedFields(t, "first_metric_name",
+		map[string]interface{}{"a_bucket": int64(0)},
+		map[string]string{"tag_name": "tag_value", "gt": bucketNegInf, "le": "0"})
+	acc.AssertContainsTaggedFields(t, "first_metric_name",
+		map[string]interface{}{"a_bucket": int64(2)},
+		map[string]string{"tag_name": "tag_value", "gt": "10", "le": "20"})
+	acc.AssertContainsTaggedFields(t, "first_metric_name",
+		map[string]interface{}{"a_bucket": int64(0)},
+		map[string]string{"tag_name": "tag_value", "gt": "40", "le": bucketInf})
+}
+
+// TestHistogramSumCount tests the sum and count fields
+func TestHistogramSumCount(t *testing.T) {
+	var cfg []config
+	cfg = append(cfg, config{Metric: "first_metric_name", Fields: []string{"a"}, Buckets: []float64{0.0, 10.0, 20.0, 30.0, 40.0}})
+	histogram := NewTestHistogram(cfg, false).(*HistogramAggregator)
+	histogram.SumCount = true
+
+	acc := &testutil.Accumulator{}
+
+	histogram.Add(firstMetric1)
+	histogram.Add(firstMetric2)
+	histogram.Push(acc)
+
+	sum := 15.3
+	sum += 15.9
+
+	if len(acc.Metrics) != 7 {
+		assert.Fail(t, "Incorrect number of metrics")
+	}
+	acc.AssertContainsTaggedFields(t, "first_metric_name",
+		map[string]interface{}{"a_sum": sum, "a_count": int64(2)},
+		map[string]string{"tag_name": "tag_value"})
+}
+
+// TestHistogramType tests the histogram metrics
+func TestHistogramType(t *testing.T) {
+	var cfg []config
+	cfg = append(cfg, config{Metric: "first_metric_name", Fields: []string{"a"}, Buckets: []float64{0.0, 10.0, 20.0}})
+	histogram := NewTestHistogram(cfg, false).(*HistogramAggregator)
+	histogram.HistogramType = true
+
+	acc := &testutil.Accumulator{}
+
+	histogram.Add(firstMetric1)
+	histogram.Add(firstMetric2)
+	histogram.Push(acc)
+
+	sum := 15.3
+	sum += 15.9
+
+	if len(acc.Metrics) != 1 {
+		assert.Fail(t, "Incorrect number of metrics")
+	}
+	acc.AssertContainsTaggedFields(t, "first_metric_name_a",
+		map[string]interface{}{
+			"0":     int64(0),
+			"10":    int64(0),
+			"20":    int64(2),
+			"count": int64(2),
+			"sum":   sum,
+		},
+		map[string]string{"tag_name": "tag_value"})
+}
+
+// TestGeneratedBuckets tests the generation of linear and exponential buckets
+func TestGeneratedBuckets(t *testing.T) {
+	histogram := NewHistogramAggregator().(*HistogramAggregator)
+	histogram.Configs = []config{
+		{Metric: "linear", BucketType: "linear", BucketStart: -10, BucketWidth: 5, BucketCount: 4},
+		{Metric: "exponential", BucketType: "exponential", BucketStart: 0.5, BucketFactor: 2, BucketCount: 4},
+	}
+	require.NoError(t, histogram.Init())
+
+	assert.Equal(t, buckets{-10, -5, 0, 5}, histogram.Configs[0].Buckets)
+	assert.Equal(t, buckets{0.5, 1, 2, 4}, histogram.Configs[1].Buckets)
+}
+
+// TestGeneratedBucketsInvalid tests the validation of generated bucket configs
+func TestGeneratedBucketsInvalid(t *testing.T) {
+	configs := []config{
+		{Metric: "m", BucketType: "linear", BucketWidth: 1, BucketCount: 0},
+		{Metric: "m", BucketType: "linear", BucketWidth: 0, BucketCount: 3},
+		{Metric: "m", BucketType: "exponential", BucketStart: 0, BucketFactor: 2, BucketCount: 3},
+		{Metric: "m", BucketType: "exponential", BucketStart: 1, BucketFactor: 1, BucketCount: 3},
+		{Metric: "m", BucketType: "quadratic", BucketCount: 3},
+		{Metric: "m", BucketType: "linear", BucketWidth: 1, BucketCount: 3, Buckets: []float64{1.0}},
+	}
+
+	for _, cfg := range configs {
+		histogram := NewHistogramAggregator().(*HistogramAggregator)
+		histogram.Configs = []config{cfg}
+		assert.Error(t, histogram.Init())
+	}
+}
+
 // TestWrongBucketsOrder tests the calling panic with incorrect order of buckets
 func TestWrongBucketsOrder(t *testing.T) {
 	defer func() {
@@ -0,0 +1,154 @@
+package quantile
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/caio/go-tdigest"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+type Quantile struct {
+	Quantiles   []float64 `toml:"quantiles"`
+	Compression float64   `toml:"compression"`
+
+	cache    map[uint64]aggregate
+	suffixes []string
+}
+
+type aggregate struct {
+	name   string
+	tags   map[string]string
+	fields map[string]*tdigest.TDigest
+}
+
+func NewQuantile() *Quantile {
+	q := &Quantile{
+		Quantiles:   []float64{0.5, 0.9, 0.99, 0.999},
+		Compression: 100,
+	}
+	q.Reset()
+	return q
+}
+
+var sampleConfig = `
+  ## General Aggregator Arguments:
+  ## The period on which to flush & clear the aggregator.
+  period = "30s"
+  ## If true, the original metric will be dropped by the
+  ## aggregator and will not get sent to the output plugins.
+  drop_original = false
+
+  ## Quantiles to output in the range [0,1]
+  # quantiles = [0.5, 0.9, 0.99, 0.999]
+
+  ## Compression of the t-digest, higher values are more accurate but use
+  ## more memory.
+  # compression = 100.0
+`
+
+func (q *Quantile) SampleConfig() string {
+	return sampleConfig
+}
+
+func (q *Quantile) Description() string {
+	return "Keep the aggregate quantiles of each metric passing through."
+}
+
+func (q *Quantile) Init() error {
+	if len(q.Quantiles) == 0 {
+		return fmt.Errorf("no quantiles specified")
+	}
+
+	if q.Compression <= 0 {
+		return fmt.Errorf("compression must be positive, got %v", q.Compression)
+	}
+
+	q.suffixes = make([]string, 0, len(q.Quantiles))
+	for _, quantile := range q.Quantiles {
+		if quantile < 0 || quantile > 1 {
+			return fmt.Errorf("quantile %v out of range [0,1]", quantile)
+		}
+		q.suffixes = append(q.suffixes, suffix(quantile))
+	}
+	return nil
+}
+
+// suffix returns the field suffix for the quantile, for example 0.999 is
+// "_p99_9".  The percentile is rounded to 6 decimal places to hide floating
+// point error, such as 0.29*100 being 28.999999999999996.
+func suffix(quantile float64) string {
+	percentile := strconv.FormatFloat(math.Round(quantile*100*1e6)/1e6, 'f', -1, 64)
+	return "_p" + strings.Replace(percentile, ".", "_", -1)
+}
+
+func (q *Quantile) Add(in telegraf.Metric) {
+	id := in.HashID()
+	a, ok := q.cache[id]
+	if !ok {
+		a = aggregate{
+			name:   in.Name(),
+			tags:   in.Tags(),
+			fields: make(map[string]*tdigest.TDigest),
+		}
+		q.cache[id] = a
+	}
+
+	for _, field := range in.FieldList() {
+		value, ok := convert(field.Value)
+		if !ok {
+			continue
+		}
+
+		digest, ok := a.fields[field.Key]
+		if !ok {
+			var err error
+			digest, err = tdigest.New(tdigest.Compression(q.Compression))
+			if err != nil {
+				continue
+			}
+			a.fields[field.Key] = digest
+		}
+		digest.Add(value)
+	}
+}
+
+func (q *Quantile) Push(acc telegraf.Accumulator) {
+	for _, aggregate := range q.cache {
+		fields := map[string]interface{}{}
+		for k, digest := range aggregate.fields {
+			for i, quantile := range q.Quantiles {
+				fields[k+q.suffixes[i]] = digest.Quantile(quantile)
+			}
+		}
+		if len(fields) > 0 {
+			acc.AddFields(aggregate.name, fields, aggregate.tags)
+		}
+	}
+}
+
+func (q *Quantile) Reset() {
+	q.cache = make(map[uint64]aggregate)
+}
+
+func convert(in interface{}) (float64, bool) {
+	switch v := in.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func init() {
+	aggregators.Add("quantile", func() telegraf.Aggregator {
+		return NewQuantile()
+	})
+}
@@ -0,0 +1,146 @@
+package quantile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuantile(t *testing.T) {
+	q := NewQuantile()
+	require.NoError(t, q.Init())
+
+	for i := 1; i <= 1000; i++ {
+		q.Add(testutil.MustMetric(
+			"http_response",
+			map[string]string{"server": "a"},
+			map[string]interface{}{
+				"response_time": float64(i),
+				"status_code":   int64(200),
+				"result":        "success",
+			},
+			time.Now(),
+		))
+	}
+
+	acc := testutil.Accumulator{}
+	q.Push(&acc)
+	require.Len(t, acc.Metrics, 1)
+
+	m := acc.Metrics[0]
+	require.Equal(t, "http_response", m.Measurement)
+	require.Equal(t, map[string]string{"server": "a"}, m.Tags)
+	require.Len(t, m.Fields, 8)
+
+	require.InDelta(t, 500.0, m.Fields["response_time_p50"], 10)
+	require.InDelta(t, 900.0, m.Fields["response_time_p90"], 10)
+	require.InDelta(t, 990.0, m.Fields["response_time_p99"], 5)
+	require.InDelta(t, 999.0, m.Fields["response_time_p99_9"], 2)
+	require.Equal(t, 200.0, m.Fields["status_code_p50"])
+}
+
+func TestQuantileSeries(t *testing.T) {
+	q := NewQuantile()
+	q.Quantiles = []float64{0.5}
+	require.NoError(t, q.Init())
+
+	for i := 0; i < 10; i++ {
+		q.Add(testutil.MustMetric("cpu", map[string]string{"cpu": "cpu0"},
+			map[string]interface{}{"usage": uint64(10)}, time.Now()))
+		q.Add(testutil.MustMetric("cpu", map[string]string{"cpu": "cpu1"},
+			map[string]interface{}{"usage": int64(20)}, time.Now()))
+	}
+
+	acc := testutil.Accumulator{}
+	q.Push(&acc)
+
+	acc.AssertContainsTaggedFields(t, "cpu",
+		map[string]interface{}{"usage_p50": 10.0},
+		map[string]string{"cpu": "cpu0"})
+	acc.AssertContainsTaggedFields(t, "cpu",
+		map[string]interface{}{"usage_p50": 20.0},
+		map[string]string{"cpu": "cpu1"})
+}
+
+func TestQuantileReset(t *testing.T) {
+	q := NewQuantile()
+	require.NoError(t, q.Init())
+
+	q.Add(testutil.MustMetric("cpu", map[string]string{},
+		map[string]interface{}{"usage": 42.0}, time.Now()))
+	q.Reset()
+
+	acc := testutil.Accumulator{}
+	q.Push(&acc)
+	require.Len(t, acc.Metrics, 0)
+}
+
+func TestQuantileNonNumeric(t *testing.T) {
+	q := NewQuantile()
+	require.NoError(t, q.Init())
+
+	q.Add(testutil.MustMetric("log", map[string]string{},
+		map[string]interface{}{"message": "hello", "ok": true}, time.Now()))
+
+	acc := testutil.Accumulator{}
+	q.Push(&acc)
+	require.Len(t, acc.Metrics, 0)
+}
+
+func TestQuantileInit(t *testing.T) {
+	var tests = []struct {
+		name        string
+		quantiles   []float64
+		compression float64
+		err         bool
+	}{
+		{
+			name:        "default",
+			quantiles:   []float64{0.5},
+			compression: 100,
+		},
+		{
+			name:        "no quantiles",
+			compression: 100,
+			err:         true,
+		},
+		{
+			name:        "out of range",
+			quantiles:   []float64{1.5},
+			compression: 100,
+			err:         true,
+		},
+		{
+			name:        "invalid compression",
+			quantiles:   []float64{0.5},
+			compression: 0,
+			err:         true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewQuantile()
+			q.Quantiles = tt.quantiles
+			q.Compression = tt.compression
+			err := q.Init()
+			if tt.err {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSuffix(t *testing.T) {
+	require.Equal(t, "_p50", suffix(0.5))
+	require.Equal(t, "_p99_9", suffix(0.999))
+	require.Equal(t, "_p0", suffix(0))
+	require.Equal(t, "_p100", suffix(1))
+	require.Equal(t, "_p29", suffix(0.29))
+	require.Equal(t, "_p57", suffix(0.57))
+	require.Equal(t, "_p7", suffix(0.07))
+	require.Equal(t, "_p99_99", suffix(0.9999))
+}
@@ -3,16 +3,25 @@ package file
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/globpath"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
 )
 
+// defaultMaxLineSize is the default maximum size, in bytes, of a single line
+// when streaming line protocol.
+// 1 MB
+const defaultMaxLineSize = 1024 * 1024
+
 type File struct {
-	Files  []string `toml:"files"`
-	parser parsers.Parser
+	Files       []string      `toml:"files"`
+	MaxLineSize internal.Size `toml:"max_line_size"`
+	parser      parsers.Parser
 
 	filenames []string
 }
@@ -26,6 +35,11 @@ const sampleConfig = `
   ##   /var/log/apache.log -> only read the apache log file
   files = ["/var/log/apache/access.log"]
 
+  ## Maximum line size allowed when reading line protocol with the "influx"
+  ## data format, longer lines are skipped.
+  ## 0 means to use the default of 1,048,576 bytes (1 mebibyte)
+  # max_line_size = "1MiB"
+
   ## The dataformat to be read from files
   ## Each data format has its own unique set of configuration options, read
   ## more about them here:
@@ -42,12 +56,26 @@ func (f *File) Description() string {
 	return "Reload and gather from file[s] on telegraf's interval."
 }
 
+func (f *File) Init() error {
+	if f.MaxLineSize.Size == 0 {
+		f.MaxLineSize.Size = defaultMaxLineSize
+	}
+	return nil
+}
+
 func (f *File) Gather(acc telegraf.Accumulator) error {
 	err := f.refreshFilePaths()
 	if err != nil {
 		return err
 	}
 	for _, k := range f.filenames {
+		if parser, ok := f.parser.(*influx.Parser); ok {
+			if err := f.streamMetrics(acc, k, parser); err != nil {
+				return err
+			}
+			continue
+		}
+
 		metrics, err := f.readMetric(k)
 		if err != nil {
 			return err
@@ -91,6 +119,41 @@ func (f *File) readMetric(filename string) ([]telegraf.Metric, error) {
 
 }
 
+// streamMetrics parses line protocol while reading the file, so that large
+// files do not need to be held in memory.  Lines that cannot be parsed, or are
+// longer than the maximum line size, are reported and skipped.
+func (f *File) streamMetrics(acc telegraf.Accumulator, filename string, p *influx.Parser) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("E! Error file: %v could not be read, %s", filename, err)
+	}
+	defer file.Close()
+
+	parser := influx.NewStreamParser(file)
+	parser.DefaultTags = p.DefaultTags
+	parser.SetMaxLineSize(int(f.MaxLineSize.Size))
+	for {
+		m, err := parser.Next()
+		if err == influx.EOF {
+			return nil
+		}
+
+		switch err.(type) {
+		case nil:
+			acc.AddFields(m.Name(), m.Fields(), m.Tags(), m.Time())
+		case *influx.ParseError:
+			acc.AddError(fmt.Errorf("E! Error file: %v, %s", filename, err))
+		default:
+			if err == influx.ErrLineTooLong {
+				acc.AddError(fmt.Errorf("E! Error file: %v, line %d longer than the maximum of %d bytes",
+					filename, parser.LineNumber(), f.MaxLineSize.Size))
+				continue
+			}
+			return fmt.Errorf("E! Error file: %v could not be read, %s", filename, err)
+		}
+	}
+}
+
 func init() {
 	inputs.Add("file", func() telegraf.Input {
 		return &File{}
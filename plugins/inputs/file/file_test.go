@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/parsers"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
@@ -59,3 +60,42 @@ func TestGrokParser(t *testing.T) {
 	err = r.Gather(&acc)
 	assert.Equal(t, len(acc.Metrics), 2)
 }
+
+func TestInfluxParserStream(t *testing.T) {
+	wd, _ := os.Getwd()
+	var acc testutil.Accumulator
+	r := File{
+		Files: []string{filepath.Join(wd, "dev/testfiles/influx_a.txt")},
+	}
+
+	nParser, err := parsers.NewParser(&parsers.Config{DataFormat: "influx"})
+	require.NoError(t, err)
+	r.SetParser(nParser)
+	require.NoError(t, r.Init())
+
+	err = r.Gather(&acc)
+	require.NoError(t, err)
+	require.Len(t, acc.Metrics, 3)
+	require.Len(t, acc.Errors, 1)
+	assert.Equal(t, map[string]interface{}{"message": "line one\nline two"}, acc.Metrics[2].Fields)
+}
+
+func TestInfluxParserStreamMaxLineSize(t *testing.T) {
+	wd, _ := os.Getwd()
+	var acc testutil.Accumulator
+	r := File{
+		Files:       []string{filepath.Join(wd, "dev/testfiles/influx_a.txt")},
+		MaxLineSize: internal.Size{Size: 42},
+	}
+
+	nParser, err := parsers.NewParser(&parsers.Config{DataFormat: "influx"})
+	require.NoError(t, err)
+	r.SetParser(nParser)
+	require.NoError(t, r.Init())
+
+	err = r.Gather(&acc)
+	require.NoError(t, err)
+	require.Len(t, acc.Metrics, 1)
+	require.Len(t, acc.Errors, 3)
+	assert.Equal(t, "mem", acc.Metrics[0].Measurement)
+}
@@ -4,6 +4,7 @@ import (
 	"compress/gzip"
 	"crypto/subtle"
 	"crypto/tls"
+	"io"
 	"io/ioutil"
 	"log"
 	"net"
@@ -18,6 +19,7 @@ import (
 	tlsint "github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
 )
 
 // defaultMaxBodySize is the default maximum request body size, in bytes.
@@ -25,6 +27,12 @@ import (
 // 500 MB
 const defaultMaxBodySize = 500 * 1024 * 1024
 
+// defaultMaxLineSize is the default maximum size, in bytes, of a single line
+// when streaming line protocol.  If a line is over this size, we will return
+// an HTTP 413 error.
+// 1 MB
+const defaultMaxLineSize = 1024 * 1024
+
 const (
 	body  = "body"
 	query = "query"
@@ -42,6 +50,7 @@ type HTTPListenerV2 struct {
 	ReadTimeout    internal.Duration `toml:"read_timeout"`
 	WriteTimeout   internal.Duration `toml:"write_timeout"`
 	MaxBodySize    internal.Size     `toml:"max_body_size"`
+	MaxLineSize    internal.Size     `toml:"max_line_size"`
 	Port           int               `toml:"port"`
 	BasicUsername  string            `toml:"basic_username"`
 	BasicPassword  string            `toml:"basic_password"`
@@ -76,6 +85,11 @@ const sampleConfig = `
   ## 0 means to use the default of 524,288,00 bytes (500 mebibytes)
   # max_body_size = "500MB"
 
+  ## Maximum line size allowed when streaming line protocol with the "influx"
+  ## data format.
+  ## 0 means to use the default of 1,048,576 bytes (1 mebibyte)
+  # max_line_size = "1MiB"
+
   ## Part of the request to consume.  Available options are "body" and
   ## "query".
   # data_source = "body"
@@ -122,6 +136,10 @@ func (h *HTTPListenerV2) Start(acc telegraf.Accumulator) error {
 		h.MaxBodySize.Size = defaultMaxBodySize
 	}
 
+	if h.MaxLineSize.Size == 0 {
+		h.MaxLineSize.Size = defaultMaxLineSize
+	}
+
 	if h.ReadTimeout.Duration < time.Second {
 		h.ReadTimeout.Duration = time.Second * 10
 	}
@@ -203,6 +221,11 @@ func (h *HTTPListenerV2) serveWrite(res http.ResponseWriter, req *http.Request)
 		return
 	}
 
+	if parser, ok := h.Parser.(*influx.Parser); ok && strings.ToLower(h.DataSource) != query {
+		h.streamBody(res, req, parser)
+		return
+	}
+
 	var bytes []byte
 	var ok bool
 
@@ -231,7 +254,80 @@ func (h *HTTPListenerV2) serveWrite(res http.ResponseWriter, req *http.Request)
 	res.WriteHeader(http.StatusNoContent)
 }
 
+// streamBody parses line protocol as it is read from the request body so
+// that the body does not need to be held in memory.
+func (h *HTTPListenerV2) streamBody(res http.ResponseWriter, req *http.Request, p *influx.Parser) {
+	body, ok := h.openBody(res, req)
+	if !ok {
+		return
+	}
+	defer body.Close()
+
+	parser := influx.NewStreamParser(body)
+	parser.DefaultTags = p.DefaultTags
+	parser.SetMaxLineSize(int(h.MaxLineSize.Size))
+
+	var parseErr error
+	for {
+		m, err := parser.Next()
+		if err == influx.EOF {
+			break
+		}
+
+		switch err.(type) {
+		case nil:
+			h.acc.AddMetric(m)
+		case *influx.ParseError:
+			log.Printf("D! [inputs.http_listener_v2] Parse error: %v", err)
+			parseErr = err
+		default:
+			if err == influx.ErrLineTooLong {
+				log.Printf("D! [inputs.http_listener_v2] Line %d longer than the maximum of %d bytes",
+					parser.LineNumber(), h.MaxLineSize.Size)
+				parseErr = err
+				continue
+			}
+			log.Printf("D! [inputs.http_listener_v2] Error reading body: %v", err)
+			if internal.IsBodyTooLarge(err) {
+				tooLarge(res)
+			} else {
+				badRequest(res)
+			}
+			return
+		}
+	}
+
+	if parseErr != nil {
+		badRequest(res)
+		return
+	}
+	res.WriteHeader(http.StatusNoContent)
+}
+
 func (h *HTTPListenerV2) collectBody(res http.ResponseWriter, req *http.Request) ([]byte, bool) {
+	body, ok := h.openBody(res, req)
+	if !ok {
+		return nil, false
+	}
+	defer body.Close()
+
+	bytes, err := ioutil.ReadAll(body)
+	if err != nil {
+		log.Printf("D! [inputs.http_listener_v2] Error reading body: %v", err)
+		if internal.IsBodyTooLarge(err) {
+			tooLarge(res)
+		} else {
+			badRequest(res)
+		}
+		return nil, false
+	}
+
+	return bytes, true
+}
+
+// openBody returns the request body, decompressed if required, limited to
+// the maximum body size.
+func (h *HTTPListenerV2) openBody(res http.ResponseWriter, req *http.Request) (io.ReadCloser, bool) {
 	body := req.Body
 
 	// Handle gzip request bodies
@@ -243,17 +339,9 @@ func (h *HTTPListenerV2) collectBody(res http.ResponseWriter, req *http.Request)
 			badRequest(res)
 			return nil, false
 		}
-		defer body.Close()
 	}
 
-	body = http.MaxBytesReader(res, body, h.MaxBodySize.Size)
-	bytes, err := ioutil.ReadAll(body)
-	if err != nil {
-		tooLarge(res)
-		return nil, false
-	}
-
-	return bytes, true
+	return http.MaxBytesReader(res, body, h.MaxBodySize.Size), true
 }
 
 func (h *HTTPListenerV2) collectQuery(res http.ResponseWriter, req *http.Request) ([]byte, bool) {
@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"crypto/tls"
 	"crypto/x509"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"runtime"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -302,6 +304,46 @@ func TestWriteHTTPGzippedData(t *testing.T) {
 	}
 }
 
+// test that a body larger than the maximum without a content length is
+// rejected once the limit is reached
+func TestWriteHTTPChunkedBodyTooLarge(t *testing.T) {
+	listener := newTestHTTPListenerV2()
+	listener.MaxBodySize = internal.Size{Size: 4096}
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	// hide the length of the body so that it is sent chunked
+	body := struct{ io.Reader }{strings.NewReader(strings.Repeat(testMsg, 100))}
+	resp, err := http.Post(createURL(listener, "http", "/write", ""), "", body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.EqualValues(t, 413, resp.StatusCode)
+}
+
+// test that a corrupt gzip body is a bad request
+func TestWriteHTTPGzippedDataCorrupt(t *testing.T) {
+	listener := newTestHTTPListenerV2()
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	data, err := ioutil.ReadFile("./testdata/testmsgs.gz")
+	require.NoError(t, err)
+
+	req, err := http.NewRequest("POST", createURL(listener, "http", "/write", ""), bytes.NewBuffer(data[:len(data)/2]))
+	require.NoError(t, err)
+	req.Header.Set("Content-Encoding", "gzip")
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.EqualValues(t, 400, resp.StatusCode)
+}
+
 // writes 25,000 metrics to the listener with 10 different writers
 func TestWriteHTTPHighTraffic(t *testing.T) {
 	if runtime.GOOS == "darwin" {
@@ -363,6 +405,52 @@ func TestWriteHTTPInvalid(t *testing.T) {
 	require.EqualValues(t, 400, resp.StatusCode)
 }
 
+func TestWriteHTTPInvalidContinues(t *testing.T) {
+	listener := newTestHTTPListenerV2()
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	// post an invalid line followed by a valid one
+	resp, err := http.Post(createURL(listener, "http", "/write", "db=mydb"), "", bytes.NewBuffer([]byte(badMsg+testMsg)))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.EqualValues(t, 400, resp.StatusCode)
+
+	acc.Wait(1)
+	acc.AssertContainsTaggedFields(t, "cpu_load_short",
+		map[string]interface{}{"value": float64(12)},
+		map[string]string{"host": "server01"},
+	)
+}
+
+func TestWriteHTTPMaxLineSize(t *testing.T) {
+	listener := newTestHTTPListenerV2()
+	listener.MaxLineSize = internal.Size{Size: 100}
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	// post lines around one longer than the maximum line size
+	longLine := "cpu_load_short,host=server07 value=" + strings.Repeat("1", 100) + "\n"
+	resp, err := http.Post(createURL(listener, "http", "/write", "db=mydb"), "", bytes.NewBuffer([]byte(testMsg+longLine+testMsgs)))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.EqualValues(t, 400, resp.StatusCode)
+
+	hostTags := []string{"server01", "server02", "server03",
+		"server04", "server05", "server06"}
+	acc.Wait(len(hostTags))
+	for _, hostTag := range hostTags {
+		acc.AssertContainsTaggedFields(t, "cpu_load_short",
+			map[string]interface{}{"value": float64(12)},
+			map[string]string{"host": hostTag},
+		)
+	}
+}
+
 func TestWriteHTTPEmpty(t *testing.T) {
 	listener := newTestHTTPListenerV2()
 
@@ -1,7 +1,6 @@
 package http_listener
 
 import (
-	"bytes"
 	"compress/gzip"
 	"crypto/subtle"
 	"crypto/tls"
@@ -56,10 +55,7 @@ type HTTPListener struct {
 
 	listener net.Listener
 
-	handler *influx.MetricHandler
-	parser  *influx.Parser
-	acc     telegraf.Accumulator
-	pool    *pool
+	acc telegraf.Accumulator
 
 	BytesRecv       selfstat.Stat
 	RequestsServed  selfstat.Stat
@@ -71,7 +67,6 @@ type HTTPListener struct {
 	QueriesRecv     selfstat.Stat
 	PingsRecv       selfstat.Stat
 	NotFoundsServed selfstat.Stat
-	BuffersCreated  selfstat.Stat
 	AuthFailures    selfstat.Stat
 
 	longLines selfstat.Stat
@@ -117,7 +112,6 @@ func (h *HTTPListener) Description() string {
 }
 
 func (h *HTTPListener) Gather(_ telegraf.Accumulator) error {
-	h.BuffersCreated.Set(h.pool.ncreated())
 	return nil
 }
 
@@ -139,7 +133,6 @@ func (h *HTTPListener) Start(acc telegraf.Accumulator) error {
 	h.QueriesRecv = selfstat.Register("http_listener", "queries_received", tags)
 	h.PingsRecv = selfstat.Register("http_listener", "pings_received", tags)
 	h.NotFoundsServed = selfstat.Register("http_listener", "not_founds_served", tags)
-	h.BuffersCreated = selfstat.Register("http_listener", "buffers_created", tags)
 	h.AuthFailures = selfstat.Register("http_listener", "auth_failures", tags)
 	h.longLines = selfstat.Register("http_listener", "long_lines", tags)
 
@@ -158,7 +151,6 @@ func (h *HTTPListener) Start(acc telegraf.Accumulator) error {
 	}
 
 	h.acc = acc
-	h.pool = NewPool(200, int(h.MaxLineSize.Size))
 
 	tlsConf, err := h.ServerConfig.TLSConfig()
 	if err != nil {
@@ -185,9 +177,6 @@ func (h *HTTPListener) Start(acc telegraf.Accumulator) error {
 	h.listener = listener
 	h.Port = listener.Addr().(*net.TCPAddr).Port
 
-	h.handler = influx.NewMetricHandler()
-	h.parser = influx.NewParser(h.handler)
-
 	h.wg.Add(1)
 	go func() {
 		defer h.wg.Done()
@@ -273,108 +262,60 @@ func (h *HTTPListener) serveWrite(res http.ResponseWriter, req *http.Request) {
 	}
 	body = http.MaxBytesReader(res, body, h.MaxBodySize.Size)
 
-	var return400 bool
-	var hangingBytes bool
-	buf := h.pool.get()
-	defer h.pool.put(buf)
-	bufStart := 0
-	for {
-		n, err := io.ReadFull(body, buf[bufStart:])
-		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
-			log.Println("D! " + err.Error())
-			// problem reading the request body
-			badRequest(res, err.Error())
-			return
-		}
-		h.BytesRecv.Incr(int64(n))
+	parser := influx.NewStreamParser(&countingReader{r: body, stat: h.BytesRecv})
+	parser.SetTimeFunc(func() time.Time { return now })
+	parser.SetTimePrecision(getPrecisionMultiplier(precision))
+	parser.SetMaxLineSize(int(h.MaxLineSize.Size))
 
-		if err == io.EOF {
-			if return400 {
-				badRequest(res, "")
-			} else {
-				res.WriteHeader(http.StatusNoContent)
-			}
-			return
+	var parseErr error
+	for {
+		m, err := parser.Next()
+		if err == influx.EOF {
+			break
 		}
 
-		if hangingBytes {
-			i := bytes.IndexByte(buf, '\n')
-			if i == -1 {
-				// still didn't find a newline, keep scanning
+		switch err := err.(type) {
+		case nil:
+			h.acc.AddFields(m.Name(), m.Fields(), m.Tags(), m.Time())
+		case *influx.ParseError:
+			log.Println("D! " + err.Error())
+			parseErr = fmt.Errorf("unable to parse: %s", err.Error())
+		default:
+			if err == influx.ErrLineTooLong {
+				h.longLines.Incr(1)
+				log.Printf("D! http_listener received a line longer than the maximum of %d bytes on line %d",
+					h.MaxLineSize.Size, parser.LineNumber())
+				parseErr = err
 				continue
 			}
-			// rotate the bit remaining after the first newline to the front of the buffer
-			i++ // start copying after the newline
-			bufStart = len(buf) - i
-			if bufStart > 0 {
-				copy(buf, buf[i:])
-			}
-			hangingBytes = false
-			continue
-		}
-
-		if err == io.ErrUnexpectedEOF {
-			// finished reading the request body
-			err = h.parse(buf[:n+bufStart], now, precision)
-			if err != nil {
-				log.Println("D! "+err.Error(), bufStart+n)
-				return400 = true
-			}
-			if return400 {
-				if err != nil {
-					badRequest(res, err.Error())
-				} else {
-					badRequest(res, "")
-				}
+			log.Println("D! " + err.Error())
+			// problem reading the request body
+			if internal.IsBodyTooLarge(err) {
+				tooLarge(res)
 			} else {
-				res.WriteHeader(http.StatusNoContent)
+				badRequest(res, err.Error())
 			}
 			return
 		}
-
-		// if we got down here it means that we filled our buffer, and there
-		// are still bytes remaining to be read. So we will parse up until the
-		// final newline, then push the rest of the bytes into the next buffer.
-		i := bytes.LastIndexByte(buf, '\n')
-		if i == -1 {
-			h.longLines.Incr(1)
-			// drop any line longer than the max buffer size
-			log.Printf("D! http_listener received a single line longer than the maximum of %d bytes",
-				len(buf))
-			hangingBytes = true
-			return400 = true
-			bufStart = 0
-			continue
-		}
-		if err := h.parse(buf[:i+1], now, precision); err != nil {
-			log.Println("D! " + err.Error())
-			return400 = true
-		}
-		// rotate the bit remaining after the last newline to the front of the buffer
-		i++ // start copying after the newline
-		bufStart = len(buf) - i
-		if bufStart > 0 {
-			copy(buf, buf[i:])
-		}
 	}
-}
-
-func (h *HTTPListener) parse(b []byte, t time.Time, precision string) error {
-	h.mu.Lock()
-	defer h.mu.Unlock()
 
-	h.handler.SetTimePrecision(getPrecisionMultiplier(precision))
-	h.handler.SetTimeFunc(func() time.Time { return t })
-	metrics, err := h.parser.Parse(b)
-	if err != nil {
-		return fmt.Errorf("unable to parse: %s", err.Error())
+	if parseErr != nil {
+		badRequest(res, parseErr.Error())
+		return
 	}
+	res.WriteHeader(http.StatusNoContent)
+}
 
-	for _, m := range metrics {
-		h.acc.AddFields(m.Name(), m.Fields(), m.Tags(), m.Time())
-	}
+// countingReader adds the number of bytes read to stat.
+type countingReader struct {
+	r    io.Reader
+	stat selfstat.Stat
+}
 
-	return nil
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.stat.Incr(int64(n))
+	return n, err
 }
 
 func tooLarge(res http.ResponseWriter) {
@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"crypto/tls"
 	"crypto/x509"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"runtime"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -335,6 +337,46 @@ func TestWriteHTTPGzippedData(t *testing.T) {
 	}
 }
 
+// test that a body larger than the maximum without a content length is
+// rejected once the limit is reached
+func TestWriteHTTPChunkedBodyTooLarge(t *testing.T) {
+	listener := newTestHTTPListener()
+	listener.MaxBodySize = internal.Size{Size: 4096}
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	// hide the length of the body so that it is sent chunked
+	body := struct{ io.Reader }{strings.NewReader(strings.Repeat(testMsg, 100))}
+	resp, err := http.Post(createURL(listener, "http", "/write", ""), "", body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.EqualValues(t, 413, resp.StatusCode)
+}
+
+// test that a corrupt gzip body is a bad request
+func TestWriteHTTPGzippedDataCorrupt(t *testing.T) {
+	listener := newTestHTTPListener()
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	data, err := ioutil.ReadFile("./testdata/testmsgs.gz")
+	require.NoError(t, err)
+
+	req, err := http.NewRequest("POST", createURL(listener, "http", "/write", ""), bytes.NewBuffer(data[:len(data)/2]))
+	require.NoError(t, err)
+	req.Header.Set("Content-Encoding", "gzip")
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.EqualValues(t, 400, resp.StatusCode)
+}
+
 // writes 25,000 metrics to the listener with 10 different writers
 func TestWriteHTTPHighTraffic(t *testing.T) {
 	if runtime.GOOS == "darwin" {
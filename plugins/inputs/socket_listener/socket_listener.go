@@ -24,6 +24,12 @@ type setReadBufferer interface {
 	SetReadBuffer(bytes int) error
 }
 
+// splitter is implemented by parsers of formats that are not newline
+// delimited, such as msgpack.
+type splitter interface {
+	Split(data []byte, atEOF bool) (advance int, token []byte, err error)
+}
+
 type streamSocketListener struct {
 	net.Listener
 	*SocketListener
@@ -105,6 +111,9 @@ func (ssl *streamSocketListener) read(c net.Conn) {
 	defer c.Close()
 
 	scnr := bufio.NewScanner(c)
+	if s, ok := ssl.Parser.(splitter); ok {
+		scnr.Split(s.Split)
+	}
 	for {
 		if ssl.ReadTimeout != nil && ssl.ReadTimeout.Duration > 0 {
 			c.SetReadDeadline(time.Now().Add(ssl.ReadTimeout.Duration))
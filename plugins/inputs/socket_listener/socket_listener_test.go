@@ -12,7 +12,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/plugins/serializers"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/influxdata/wlog"
 	"github.com/stretchr/testify/assert"
@@ -108,6 +111,50 @@ func TestSocketListener_tcp(t *testing.T) {
 	testSocketListener(t, sl, client)
 }
 
+func TestSocketListener_tcp_msgpack(t *testing.T) {
+	defer testEmptyLog(t)()
+
+	parser, err := parsers.NewMsgpackParser(nil)
+	require.NoError(t, err)
+
+	sl := newSocketListener()
+	sl.ServiceAddress = "tcp://127.0.0.1:0"
+	sl.Parser = parser
+
+	acc := &testutil.Accumulator{}
+	err = sl.Start(acc)
+	require.NoError(t, err)
+	defer sl.Stop()
+
+	client, err := net.Dial("tcp", sl.Closer.(net.Listener).Addr().String())
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"test",
+			map[string]string{"foo": "bar"},
+			map[string]interface{}{"v": "a\nb"},
+			time.Unix(0, 123456789),
+		),
+		testutil.MustMetric(
+			"test",
+			map[string]string{"foo": "baz"},
+			map[string]interface{}{"v": uint64(2)},
+			time.Unix(0, 123456790),
+		),
+	}
+
+	serializer, err := serializers.NewMsgpackSerializer()
+	require.NoError(t, err)
+	buf, err := serializer.SerializeBatch(expected)
+	require.NoError(t, err)
+	_, err = client.Write(buf)
+	require.NoError(t, err)
+
+	acc.Wait(2)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
 func TestSocketListener_udp(t *testing.T) {
 	defer testEmptyLog(t)()
 
@@ -0,0 +1,139 @@
+package influx
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+var (
+	// ErrLineTooLong is returned when a line exceeds the maximum line size.
+	ErrLineTooLong = errors.New("line too long")
+)
+
+// lineReader reads one line of line protocol at a time.  Lines end with a
+// newline that is not within a quoted string field, so string fields may
+// contain newlines.
+type lineReader struct {
+	reader  *bufio.Reader
+	buf     []byte
+	maxSize int
+
+	// n and lines are the number of bytes and newlines consumed by the last
+	// call to ReadLine.
+	n     int
+	lines int
+}
+
+func newLineReader(r io.Reader) *lineReader {
+	return &lineReader{
+		reader: bufio.NewReader(r),
+	}
+}
+
+// ReadLine returns the next line including the trailing newline.  The
+// returned slice is only valid until the next call.  If the line is larger
+// than the maximum size it is discarded and ErrLineTooLong is returned.
+func (r *lineReader) ReadLine() ([]byte, error) {
+	r.buf = r.buf[:0]
+	r.n = 0
+	r.lines = 0
+
+	var s lineScanner
+	var tooLong bool
+	for {
+		chunk, err := r.reader.ReadSlice('\n')
+		if err != nil && err != bufio.ErrBufferFull && err != io.EOF {
+			return nil, err
+		}
+
+		r.n += len(chunk)
+
+		var done bool
+		for _, c := range chunk {
+			if c == '\n' {
+				r.lines++
+			}
+			done = s.scan(c)
+		}
+
+		if !tooLong {
+			if r.maxSize > 0 && len(r.buf)+len(chunk) > r.maxSize {
+				tooLong = true
+				r.buf = r.buf[:0]
+			} else {
+				r.buf = append(r.buf, chunk...)
+			}
+		}
+
+		if err == io.EOF {
+			if len(r.buf) == 0 && !tooLong {
+				return nil, io.EOF
+			}
+			done = true
+		}
+
+		if done {
+			if tooLong {
+				return nil, ErrLineTooLong
+			}
+			return r.buf, nil
+		}
+	}
+}
+
+// lineScanner tracks enough of the line protocol syntax to find the newline
+// ending a line.
+type lineScanner struct {
+	started  bool
+	comment  bool
+	fieldset bool
+	quoted   bool
+	escape   bool
+	prev     byte
+}
+
+// scan processes the next byte of the line and returns true if it ends the
+// line.
+func (s *lineScanner) scan(c byte) bool {
+	if c == '\n' && !s.quoted {
+		return true
+	}
+
+	if !s.started {
+		switch c {
+		case ' ', '\t', '\v', '\f', '\r':
+			return false
+		case '#':
+			s.comment = true
+		}
+		s.started = true
+	}
+
+	switch {
+	case s.comment:
+	case s.escape:
+		s.escape = false
+		if c == '\n' {
+			return true
+		}
+		c = 0
+	case s.quoted:
+		switch c {
+		case '\\':
+			s.escape = true
+		case '"':
+			s.quoted = false
+		}
+	case c == '\\':
+		s.escape = true
+	case !s.fieldset:
+		if c == ' ' || c == '\t' {
+			s.fieldset = true
+		}
+	case c == '"' && s.prev == '=':
+		s.quoted = true
+	}
+	s.prev = c
+	return false
+}
@@ -3,10 +3,13 @@ package influx
 import (
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 )
 
 const (
@@ -133,3 +136,114 @@ func (p *Parser) applyDefaultTags(metrics []telegraf.Metric) {
 		}
 	}
 }
+
+// StreamParser is an influx line protocol parser that reads from an
+// io.Reader and returns one metric at a time, only a single line is held in
+// memory.
+type StreamParser struct {
+	DefaultTags map[string]string
+
+	reader  *lineReader
+	machine *machine
+	handler *MetricHandler
+
+	data     []byte
+	lineno   int
+	nextLine int
+	offset   int
+	next     int
+}
+
+// NewStreamParser returns a StreamParser reading line protocol from r.
+func NewStreamParser(r io.Reader) *StreamParser {
+	handler := NewMetricHandler()
+	return &StreamParser{
+		reader:   newLineReader(r),
+		machine:  NewMachine(handler),
+		handler:  handler,
+		nextLine: 1,
+	}
+}
+
+// SetTimeFunc sets the function used to timestamp metrics without a
+// timestamp.
+func (p *StreamParser) SetTimeFunc(f metric.TimeFunc) {
+	p.handler.SetTimeFunc(f)
+}
+
+// SetTimePrecision sets the precision of the timestamps in the input.
+func (p *StreamParser) SetTimePrecision(precision time.Duration) {
+	p.handler.SetTimePrecision(precision)
+}
+
+// SetMaxLineSize sets the maximum size of a line in bytes, longer lines are
+// skipped and reported with ErrLineTooLong.  The default of 0 is unlimited.
+func (p *StreamParser) SetMaxLineSize(size int) {
+	p.reader.maxSize = size
+}
+
+// Next returns the next metric, or EOF once the input is exhausted.
+//
+// If a line cannot be parsed a *ParseError, or ErrLineTooLong, is returned
+// and the line is skipped; Next can be called again to continue with the
+// following line.  Any other error is from the underlying reader.
+func (p *StreamParser) Next() (telegraf.Metric, error) {
+	for {
+		err := p.machine.Next()
+		if err == EOF {
+			err = p.readLine()
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if err != nil {
+			p.handler.Reset()
+			return nil, &ParseError{
+				Offset:     p.offset + p.machine.Position(),
+				LineOffset: p.machine.LineOffset(),
+				LineNumber: p.lineno + p.machine.LineNumber() - 1,
+				Column:     p.machine.Column(),
+				msg:        err.Error(),
+				buf:        string(p.data),
+			}
+		}
+
+		m, err := p.handler.Metric()
+		if err != nil {
+			return nil, err
+		}
+
+		if m == nil {
+			continue
+		}
+
+		for k, v := range p.DefaultTags {
+			if !m.HasTag(k) {
+				m.AddTag(k, v)
+			}
+		}
+		return m, nil
+	}
+}
+
+// LineNumber returns the line number the last metric or error started on.
+func (p *StreamParser) LineNumber() int {
+	return p.lineno
+}
+
+func (p *StreamParser) readLine() error {
+	p.offset = p.next
+	p.lineno = p.nextLine
+
+	line, err := p.reader.ReadLine()
+	p.next += p.reader.n
+	p.nextLine += p.reader.lines
+	p.data = line
+	p.machine.SetData(line)
+	if err == io.EOF {
+		return EOF
+	}
+	return err
+}
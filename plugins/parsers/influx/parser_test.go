@@ -1,6 +1,9 @@
 package influx
 
 import (
+	"bytes"
+	"errors"
+	"io"
 	"strconv"
 	"strings"
 	"testing"
@@ -803,3 +806,216 @@ func TestParserErrorString(t *testing.T) {
 		})
 	}
 }
+
+func TestStreamParser(t *testing.T) {
+	for _, tt := range ptests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewStreamParser(bytes.NewReader(tt.input))
+			parser.SetTimeFunc(DefaultTime)
+			if tt.timeFunc != nil {
+				parser.SetTimeFunc(tt.timeFunc)
+			}
+			if tt.precision > 0 {
+				parser.SetTimePrecision(tt.precision)
+			}
+
+			var i int
+			for {
+				m, err := parser.Next()
+				if err == EOF {
+					break
+				}
+
+				if tt.err != nil {
+					expected := tt.err.(*ParseError)
+					actual, ok := err.(*ParseError)
+					require.True(t, ok, "expected ParseError, got %v", err)
+					require.Equal(t, expected.Offset, actual.Offset)
+					require.Equal(t, expected.LineNumber, actual.LineNumber)
+					require.Equal(t, expected.Column, actual.Column)
+					require.Equal(t, expected.msg, actual.msg)
+					return
+				}
+
+				require.NoError(t, err)
+				require.True(t, i < len(tt.metrics), "unexpected metric %v", m)
+				expected := tt.metrics[i]
+				require.Equal(t, expected.Name(), m.Name())
+				require.Equal(t, expected.Tags(), m.Tags())
+				require.Equal(t, expected.Fields(), m.Fields())
+				require.Equal(t, expected.Time(), m.Time())
+				i++
+			}
+			require.Nil(t, tt.err)
+			require.Equal(t, len(tt.metrics), i)
+		})
+	}
+}
+
+func TestStreamParserErrorString(t *testing.T) {
+	var ptests = []struct {
+		name      string
+		input     []byte
+		errString string
+	}{
+		{
+			name:      "multiple line error",
+			input:     []byte("cpu value=42\ncpu value=invalid\ncpu value=42"),
+			errString: `metric parse error: expected field at 2:11: "cpu value=invalid"`,
+		},
+		{
+			name:      "handler error",
+			input:     []byte("cpu value=9223372036854775808i\ncpu value=42"),
+			errString: `metric parse error: value out of range at 1:31: "cpu value=9223372036854775808i"`,
+		},
+		{
+			name:      "error after string with newline",
+			input:     []byte("cpu value=\"a\nb\"\n# comment\ncpu value=invalid\n"),
+			errString: `metric parse error: expected field at 4:11: "cpu value=invalid"`,
+		},
+	}
+
+	for _, tt := range ptests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewStreamParser(bytes.NewReader(tt.input))
+
+			var err error
+			for err == nil {
+				_, err = parser.Next()
+			}
+			require.Equal(t, tt.errString, err.Error())
+		})
+	}
+}
+
+func TestStreamParserContinuesAfterError(t *testing.T) {
+	input := "cpu value=1\ncpu value=invalid\ncpu value=\"multi\nline\" 3\ncpu value=4\n"
+	parser := NewStreamParser(strings.NewReader(input))
+	parser.SetTimeFunc(DefaultTime)
+
+	m, err := parser.Next()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"value": 1.0}, m.Fields())
+
+	_, err = parser.Next()
+	perr, ok := err.(*ParseError)
+	require.True(t, ok)
+	require.Equal(t, 2, perr.LineNumber)
+
+	m, err = parser.Next()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"value": "multi\nline"}, m.Fields())
+	require.Equal(t, time.Unix(0, 3), m.Time())
+
+	m, err = parser.Next()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"value": 4.0}, m.Fields())
+	require.Equal(t, 5, parser.LineNumber())
+
+	_, err = parser.Next()
+	require.Equal(t, EOF, err)
+}
+
+func TestStreamParserMaxLineSize(t *testing.T) {
+	input := "cpu value=1\ncpu " + strings.Repeat("a", 5000) + "=2\ncpu value=3"
+	parser := NewStreamParser(strings.NewReader(input))
+	parser.SetMaxLineSize(64)
+
+	m, err := parser.Next()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"value": 1.0}, m.Fields())
+
+	_, err = parser.Next()
+	require.Equal(t, ErrLineTooLong, err)
+	require.Equal(t, 2, parser.LineNumber())
+
+	m, err = parser.Next()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"value": 3.0}, m.Fields())
+
+	_, err = parser.Next()
+	require.Equal(t, EOF, err)
+}
+
+func TestStreamParserDefaultTags(t *testing.T) {
+	parser := NewStreamParser(strings.NewReader("cpu,host=a value=1\ncpu value=2\n"))
+	parser.DefaultTags = map[string]string{"host": "default"}
+
+	m, err := parser.Next()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"host": "a"}, m.Tags())
+
+	m, err = parser.Next()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"host": "default"}, m.Tags())
+}
+
+type errorReader struct{}
+
+func (errorReader) Read(p []byte) (int, error) {
+	return 0, errors.New("read error")
+}
+
+func TestStreamParserReaderError(t *testing.T) {
+	parser := NewStreamParser(io.MultiReader(strings.NewReader("cpu value=1\n"), errorReader{}))
+
+	_, err := parser.Next()
+	require.NoError(t, err)
+
+	_, err = parser.Next()
+	require.EqualError(t, err, "read error")
+}
+
+func TestLineScanner(t *testing.T) {
+	var tests = []struct {
+		name  string
+		input string
+		lines []string
+	}{
+		{
+			name:  "simple",
+			input: "cpu value=1\ncpu value=2",
+			lines: []string{"cpu value=1\n", "cpu value=2"},
+		},
+		{
+			name:  "newline in string field",
+			input: "cpu a=\"x\ny\",b=1\ncpu value=2\n",
+			lines: []string{"cpu a=\"x\ny\",b=1\n", "cpu value=2\n"},
+		},
+		{
+			name:  "escaped quote in string field",
+			input: "cpu a=\"x\\\"\ny\"\ncpu value=2\n",
+			lines: []string{"cpu a=\"x\\\"\ny\"\n", "cpu value=2\n"},
+		},
+		{
+			name:  "quote in measurement and tags",
+			input: "c\"pu,t=\"x value=1\ncpu value=2\n",
+			lines: []string{"c\"pu,t=\"x value=1\n", "cpu value=2\n"},
+		},
+		{
+			name:  "quote in comment",
+			input: "# a=\"b\ncpu value=2\n",
+			lines: []string{"# a=\"b\n", "cpu value=2\n"},
+		},
+		{
+			name:  "escaped space in measurement",
+			input: "c\\ pu,t=\"x value=1\ncpu value=2\n",
+			lines: []string{"c\\ pu,t=\"x value=1\n", "cpu value=2\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newLineReader(strings.NewReader(tt.input))
+			var lines []string
+			for {
+				line, err := r.ReadLine()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				lines = append(lines, string(line))
+			}
+			require.Equal(t, tt.lines, lines)
+		})
+	}
+}
@@ -0,0 +1,493 @@
+package msgpack
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+var (
+	// ErrNoMetric is returned when no metric is found in the input
+	ErrNoMetric = errors.New("no metric in input")
+
+	errShortBuffer = errors.New("unexpected end of data")
+)
+
+const extTimestamp = -1
+
+// maxDepth is the maximum nesting of arrays and maps in skipped objects.
+const maxDepth = 64
+
+// Parser decodes MessagePack encoded metrics as written by the msgpack
+// serializer.  Each metric is a map containing the keys "name", "time",
+// "tags" and "fields"; any number of metrics may be concatenated.
+type Parser struct {
+	DefaultTags map[string]string
+	TimeFunc    func() time.Time
+}
+
+func NewParser() *Parser {
+	return &Parser{
+		TimeFunc: time.Now,
+	}
+}
+
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	metrics := make([]telegraf.Metric, 0)
+	d := &decoder{buf: buf}
+	for d.off < len(d.buf) {
+		start := d.off
+		m, err := p.decodeMetric(d)
+		if err != nil {
+			return nil, fmt.Errorf("metric at offset %d: %v", start, err)
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) < 1 {
+		return nil, ErrNoMetric
+	}
+
+	return metrics[0], nil
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
+// Split can be used as a bufio.SplitFunc returning one complete encoded
+// metric per token, it allows reading metrics from a stream where messages
+// are not newline delimited.
+func (p *Parser) Split(data []byte, atEOF bool) (int, []byte, error) {
+	if len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	d := &decoder{buf: data}
+	err := d.skip(0)
+	switch {
+	case err == errShortBuffer && !atEOF:
+		return 0, nil, nil
+	case err != nil:
+		return 0, nil, err
+	}
+	return d.off, data[:d.off], nil
+}
+
+func (p *Parser) decodeMetric(d *decoder) (telegraf.Metric, error) {
+	n, err := d.readMapLen()
+	if err != nil {
+		return nil, err
+	}
+
+	var name string
+	var tm time.Time
+	tags := make(map[string]string)
+	fields := make(map[string]interface{})
+	for i := 0; i < n; i++ {
+		key, err := d.readString()
+		if err != nil {
+			return nil, err
+		}
+
+		switch key {
+		case "name":
+			name, err = d.readString()
+		case "time":
+			tm, err = d.readTime()
+		case "tags":
+			err = d.readTags(tags)
+		case "fields":
+			err = d.readFields(fields)
+		default:
+			err = d.skip(0)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %v", key, err)
+		}
+	}
+
+	if name == "" {
+		return nil, errors.New("missing metric name")
+	}
+
+	for k, v := range p.DefaultTags {
+		if _, ok := tags[k]; !ok {
+			tags[k] = v
+		}
+	}
+
+	if tm.IsZero() {
+		tm = p.now()
+	}
+
+	return metric.New(name, tags, fields, tm)
+}
+
+func (p *Parser) now() time.Time {
+	if p.TimeFunc == nil {
+		return time.Now()
+	}
+	return p.TimeFunc()
+}
+
+type decoder struct {
+	buf []byte
+	off int
+}
+
+func (d *decoder) next(n int) ([]byte, error) {
+	if n < 0 || len(d.buf)-d.off < n {
+		return nil, errShortBuffer
+	}
+	b := d.buf[d.off : d.off+n]
+	d.off += n
+	return b, nil
+}
+
+func (d *decoder) readByte() (byte, error) {
+	b, err := d.next(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (d *decoder) readUint(size int) (uint64, error) {
+	b, err := d.next(size)
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	default:
+		return binary.BigEndian.Uint64(b), nil
+	}
+}
+
+func (d *decoder) readMapLen() (int, error) {
+	c, err := d.readByte()
+	if err != nil {
+		return 0, err
+	}
+
+	var n uint64
+	switch {
+	case c&0xf0 == 0x80:
+		return int(c & 0x0f), nil
+	case c == 0xde:
+		n, err = d.readUint(2)
+	case c == 0xdf:
+		n, err = d.readUint(4)
+	default:
+		return 0, fmt.Errorf("expected map, found format 0x%02x", c)
+	}
+	return int(n), err
+}
+
+func (d *decoder) readString() (string, error) {
+	c, err := d.readByte()
+	if err != nil {
+		return "", err
+	}
+
+	n, err := d.strLen(c)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := d.next(n)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// strLen returns the length of the str or bin object with format c.
+func (d *decoder) strLen(c byte) (int, error) {
+	var n uint64
+	var err error
+	switch {
+	case c&0xe0 == 0xa0:
+		return int(c & 0x1f), nil
+	case c == 0xd9, c == 0xc4:
+		n, err = d.readUint(1)
+	case c == 0xda, c == 0xc5:
+		n, err = d.readUint(2)
+	case c == 0xdb, c == 0xc6:
+		n, err = d.readUint(4)
+	default:
+		return 0, fmt.Errorf("expected string, found format 0x%02x", c)
+	}
+	return int(n), err
+}
+
+func (d *decoder) readTime() (time.Time, error) {
+	c, err := d.readByte()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var n int
+	switch c {
+	case 0xd6:
+		n = 4
+	case 0xd7:
+		n = 8
+	case 0xc7:
+		size, err := d.readUint(1)
+		if err != nil {
+			return time.Time{}, err
+		}
+		n = int(size)
+	default:
+		return time.Time{}, fmt.Errorf("expected timestamp, found format 0x%02x", c)
+	}
+
+	typ, err := d.readByte()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if int8(typ) != extTimestamp {
+		return time.Time{}, fmt.Errorf("expected timestamp, found extension type %d", int8(typ))
+	}
+
+	switch n {
+	case 4:
+		sec, err := d.readUint(4)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(int64(sec), 0), nil
+	case 8:
+		data, err := d.readUint(8)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(int64(data&0x3ffffffff), int64(data>>34)), nil
+	case 12:
+		nsec, err := d.readUint(4)
+		if err != nil {
+			return time.Time{}, err
+		}
+		sec, err := d.readUint(8)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(int64(sec), int64(nsec)), nil
+	default:
+		return time.Time{}, fmt.Errorf("invalid timestamp length %d", n)
+	}
+}
+
+func (d *decoder) readTags(tags map[string]string) error {
+	n, err := d.readMapLen()
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < n; i++ {
+		key, err := d.readString()
+		if err != nil {
+			return err
+		}
+		value, err := d.readString()
+		if err != nil {
+			return fmt.Errorf("tag %q: %v", key, err)
+		}
+		tags[key] = value
+	}
+	return nil
+}
+
+func (d *decoder) readFields(fields map[string]interface{}) error {
+	n, err := d.readMapLen()
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < n; i++ {
+		key, err := d.readString()
+		if err != nil {
+			return err
+		}
+		value, err := d.readValue()
+		if err != nil {
+			return fmt.Errorf("field %q: %v", key, err)
+		}
+		if value != nil {
+			fields[key] = value
+		}
+	}
+	return nil
+}
+
+// readValue decodes a field value.  Signed formats, including the fixints,
+// are returned as int64 and unsigned formats as uint64.
+func (d *decoder) readValue() (interface{}, error) {
+	c, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xe0 == 0xa0, c == 0xd9, c == 0xda, c == 0xdb, c == 0xc4, c == 0xc5, c == 0xc6:
+		n, err := d.strLen(c)
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.next(n)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xca:
+		v, err := d.readUint(4)
+		return float64(math.Float32frombits(uint32(v))), err
+	case 0xcb:
+		v, err := d.readUint(8)
+		return math.Float64frombits(v), err
+	case 0xcc:
+		return d.readUint(1)
+	case 0xcd:
+		return d.readUint(2)
+	case 0xce:
+		return d.readUint(4)
+	case 0xcf:
+		return d.readUint(8)
+	case 0xd0:
+		v, err := d.readUint(1)
+		return int64(int8(v)), err
+	case 0xd1:
+		v, err := d.readUint(2)
+		return int64(int16(v)), err
+	case 0xd2:
+		v, err := d.readUint(4)
+		return int64(int32(v)), err
+	case 0xd3:
+		v, err := d.readUint(8)
+		return int64(v), err
+	default:
+		return nil, fmt.Errorf("unsupported field format 0x%02x", c)
+	}
+}
+
+// skip advances past the next object of any type, depth is the nesting of
+// the object in skipped arrays and maps.
+func (d *decoder) skip(depth int) error {
+	if depth > maxDepth {
+		return fmt.Errorf("objects nested deeper than %d levels", maxDepth)
+	}
+
+	c, err := d.readByte()
+	if err != nil {
+		return err
+	}
+
+	var size, count uint64
+	switch {
+	case c <= 0x7f, c >= 0xe0, c == 0xc0, c == 0xc2, c == 0xc3:
+		return nil
+	case c&0xf0 == 0x80:
+		count = 2 * uint64(c&0x0f)
+	case c&0xf0 == 0x90:
+		count = uint64(c & 0x0f)
+	case c&0xe0 == 0xa0:
+		size = uint64(c & 0x1f)
+	case c == 0xc4, c == 0xd9:
+		size, err = d.readUint(1)
+	case c == 0xc5, c == 0xda:
+		size, err = d.readUint(2)
+	case c == 0xc6, c == 0xdb:
+		size, err = d.readUint(4)
+	case c == 0xc7:
+		size, err = d.readUint(1)
+		size++
+	case c == 0xc8:
+		size, err = d.readUint(2)
+		size++
+	case c == 0xc9:
+		size, err = d.readUint(4)
+		size++
+	case c == 0xca:
+		size = 4
+	case c == 0xcb:
+		size = 8
+	case c == 0xcc, c == 0xd0:
+		size = 1
+	case c == 0xcd, c == 0xd1:
+		size = 2
+	case c == 0xce, c == 0xd2:
+		size = 4
+	case c == 0xcf, c == 0xd3:
+		size = 8
+	case c == 0xd4:
+		size = 2
+	case c == 0xd5:
+		size = 3
+	case c == 0xd6:
+		size = 5
+	case c == 0xd7:
+		size = 9
+	case c == 0xd8:
+		size = 17
+	case c == 0xdc:
+		count, err = d.readUint(2)
+	case c == 0xdd:
+		count, err = d.readUint(4)
+	case c == 0xde:
+		count, err = d.readUint(2)
+		count *= 2
+	case c == 0xdf:
+		count, err = d.readUint(4)
+		count *= 2
+	default:
+		return fmt.Errorf("invalid format 0x%02x", c)
+	}
+	if err != nil {
+		return err
+	}
+
+	if size > 0 {
+		if _, err := d.next(int(size)); err != nil {
+			return err
+		}
+	}
+	for i := uint64(0); i < count; i++ {
+		if err := d.skip(depth + 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
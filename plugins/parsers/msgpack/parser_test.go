@@ -0,0 +1,239 @@
+package msgpack
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/serializers/msgpack"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func serialize(t *testing.T, metrics ...telegraf.Metric) []byte {
+	s, err := msgpack.NewSerializer()
+	require.NoError(t, err)
+	buf, err := s.SerializeBatch(metrics)
+	require.NoError(t, err)
+	return buf
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{
+				"cpu":  "cpu0",
+				"host": "localhost",
+			},
+			map[string]interface{}{
+				"usage_idle": 91.5,
+				"processes":  int64(42),
+				"small":      int64(-3),
+				"large":      int64(-1 << 62),
+				"uptime":     uint64(7),
+				"max_uint":   uint64(1<<64 - 1),
+				"state":      "running",
+				"ok":         true,
+			},
+			time.Unix(1525478795, 123456789),
+		),
+		testutil.MustMetric(
+			"mem",
+			map[string]string{},
+			map[string]interface{}{
+				"free": int64(1024),
+			},
+			time.Unix(1<<40, 5),
+		),
+	}
+
+	parser := NewParser()
+	actual, err := parser.Parse(serialize(t, metrics...))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, metrics, actual)
+}
+
+func TestParseDefaultTags(t *testing.T) {
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{
+			"host": "localhost",
+		},
+		map[string]interface{}{
+			"value": 42.0,
+		},
+		time.Unix(0, 0),
+	)
+
+	parser := NewParser()
+	parser.SetDefaultTags(map[string]string{
+		"host": "default",
+		"dc":   "us-east-1",
+	})
+	actual, err := parser.ParseLine(string(serialize(t, m)))
+	require.NoError(t, err)
+
+	expected := testutil.MustMetric(
+		"cpu",
+		map[string]string{
+			"host": "localhost",
+			"dc":   "us-east-1",
+		},
+		map[string]interface{}{
+			"value": 42.0,
+		},
+		time.Unix(0, 0),
+	)
+	testutil.RequireMetricEqual(t, expected, actual)
+}
+
+func TestParseMissingTime(t *testing.T) {
+	buf := []byte{
+		0x82,
+		0xa4, 'n', 'a', 'm', 'e', 0xa3, 'c', 'p', 'u',
+		0xa6, 'f', 'i', 'e', 'l', 'd', 's', 0x81, 0xa5, 'v', 'a', 'l', 'u', 'e', 0xcb,
+		0x3f, 0xf0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+
+	parser := NewParser()
+	parser.TimeFunc = func() time.Time {
+		return time.Unix(42, 0)
+	}
+	actual, err := parser.Parse(buf)
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{
+				"value": 1.0,
+			},
+			time.Unix(42, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestParseIgnoresUnknownKeys(t *testing.T) {
+	buf := []byte{
+		0x83,
+		0xa4, 'n', 'a', 'm', 'e', 0xa3, 'c', 'p', 'u',
+		0xa5, 'e', 'x', 't', 'r', 'a', 0x92, 0x01, 0xa1, 'x',
+		0xa6, 'f', 'i', 'e', 'l', 'd', 's', 0x81, 0xa5, 'v', 'a', 'l', 'u', 'e', 0x01,
+	}
+
+	parser := NewParser()
+	metrics, err := parser.Parse(buf)
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, map[string]interface{}{"value": int64(1)}, metrics[0].Fields())
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  []byte
+	}{
+		{
+			name: "not a map",
+			buf:  []byte{0x01},
+		},
+		{
+			name: "missing name",
+			buf:  []byte{0x80},
+		},
+		{
+			name: "truncated",
+			buf:  []byte{0x81, 0xa4, 'n', 'a', 'm', 'e', 0xa3, 'c'},
+		},
+		{
+			name: "deeply nested",
+			buf: append(append([]byte{0x82,
+				0xa4, 'n', 'a', 'm', 'e', 0xa1, 'a',
+				0xa5, 'e', 'x', 't', 'r', 'a'},
+				bytes.Repeat([]byte{0x91}, 100000)...), 0x01),
+		},
+		{
+			name: "array field",
+			buf: []byte{0x82,
+				0xa4, 'n', 'a', 'm', 'e', 0xa1, 'a',
+				0xa6, 'f', 'i', 'e', 'l', 'd', 's', 0x81, 0xa1, 'x', 0x90},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewParser()
+			_, err := parser.Parse(tt.buf)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	parser := NewParser()
+	metrics, err := parser.Parse(nil)
+	require.NoError(t, err)
+	require.Len(t, metrics, 0)
+
+	_, err = parser.ParseLine("")
+	require.Equal(t, ErrNoMetric, err)
+}
+
+func TestSplit(t *testing.T) {
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{
+			"host": "localhost",
+		},
+		map[string]interface{}{
+			"value": "line\nbreak",
+		},
+		time.Unix(0, 0),
+	)
+	buf := serialize(t, m, m, m)
+
+	parser := NewParser()
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	scanner.Split(parser.Split)
+
+	var count int
+	for scanner.Scan() {
+		actual, err := parser.ParseLine(scanner.Text())
+		require.NoError(t, err)
+		testutil.RequireMetricEqual(t, m, actual)
+		count++
+	}
+	require.NoError(t, scanner.Err())
+	require.Equal(t, 3, count)
+}
+
+func TestSplitTruncated(t *testing.T) {
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{
+			"value": 42.0,
+		},
+		time.Unix(0, 0),
+	)
+	buf := serialize(t, m)
+
+	parser := NewParser()
+	advance, token, err := parser.Split(buf[:len(buf)-1], false)
+	require.NoError(t, err)
+	require.Equal(t, 0, advance)
+	require.Nil(t, token)
+
+	_, _, err = parser.Split(buf[:len(buf)-1], true)
+	require.Error(t, err)
+}
+
+func TestSplitDeeplyNested(t *testing.T) {
+	parser := NewParser()
+	_, _, err := parser.Split(bytes.Repeat([]byte{0x91}, 100000), false)
+	require.Error(t, err)
+}
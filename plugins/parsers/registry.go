@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	syslogframing "github.com/influxdata/telegraf/internal/syslog"
 	"github.com/influxdata/telegraf/plugins/parsers/collectd"
 	"github.com/influxdata/telegraf/plugins/parsers/csv"
 	"github.com/influxdata/telegraf/plugins/parsers/dropwizard"
@@ -14,7 +15,9 @@ import (
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/parsers/json"
 	"github.com/influxdata/telegraf/plugins/parsers/logfmt"
+	"github.com/influxdata/telegraf/plugins/parsers/msgpack"
 	"github.com/influxdata/telegraf/plugins/parsers/nagios"
+	"github.com/influxdata/telegraf/plugins/parsers/syslog"
 	"github.com/influxdata/telegraf/plugins/parsers/value"
 	"github.com/influxdata/telegraf/plugins/parsers/wavefront"
 )
@@ -60,7 +63,8 @@ type Parser interface {
 // Config is a struct that covers the data types needed for all parser types,
 // and can be used to instantiate _any_ of the parsers.
 type Config struct {
-	// Dataformat can be one of: json, influx, graphite, value, nagios
+	// Dataformat can be one of: json, influx, graphite, value, nagios, msgpack,
+	// syslog
 	DataFormat string `toml:"data_format"`
 
 	// Separator only applied to Graphite data.
@@ -145,6 +149,12 @@ type Config struct {
 
 	// FormData configuration
 	FormUrlencodedTagKeys []string `toml:"form_urlencoded_tag_keys"`
+
+	// syslog configuration
+	SyslogFormat      string `toml:"syslog_format"`
+	SyslogFraming     string `toml:"syslog_framing"`
+	SyslogBestEffort  bool   `toml:"syslog_best_effort"`
+	SyslogSDSeparator string `toml:"syslog_sdparam_separator"`
 }
 
 // NewParser returns a Parser interface based on the given config.
@@ -191,6 +201,8 @@ func NewParser(config *Config) (Parser, error) {
 			config.Templates)
 	case "wavefront":
 		parser, err = NewWavefrontParser(config.DefaultTags)
+	case "msgpack":
+		parser, err = NewMsgpackParser(config.DefaultTags)
 	case "grok":
 		parser, err = newGrokParser(
 			config.MetricName,
@@ -223,6 +235,14 @@ func NewParser(config *Config) (Parser, error) {
 			config.DefaultTags,
 			config.FormUrlencodedTagKeys,
 		)
+	case "syslog":
+		parser, err = NewSyslogParser(
+			config.SyslogFormat,
+			config.SyslogFraming,
+			config.SyslogBestEffort,
+			config.SyslogSDSeparator,
+			config.DefaultTags,
+		)
 	default:
 		err = fmt.Errorf("Invalid data format: %s", config.DataFormat)
 	}
@@ -373,6 +393,38 @@ func NewLogFmtParser(metricName string, defaultTags map[string]string) (Parser,
 	return logfmt.NewParser(metricName, defaultTags), nil
 }
 
+// NewMsgpackParser returns a parser for metrics encoded by the msgpack
+// serializer.
+func NewMsgpackParser(defaultTags map[string]string) (Parser, error) {
+	parser := msgpack.NewParser()
+	parser.DefaultTags = defaultTags
+	return parser, nil
+}
+
+// NewSyslogParser returns a parser for RFC5424 or RFC3164 syslog messages,
+// framing defaults to non-transparent with one message per line.
+func NewSyslogParser(
+	format string,
+	framing string,
+	bestEffort bool,
+	separator string,
+	defaultTags map[string]string,
+) (Parser, error) {
+	f := syslogframing.NonTransparent
+	if framing != "" {
+		if err := f.UnmarshalText([]byte(framing)); err != nil {
+			return nil, fmt.Errorf("syslog_framing: %v", err)
+		}
+	}
+
+	parser, err := syslog.NewParser(format, f, bestEffort, separator)
+	if err != nil {
+		return nil, err
+	}
+	parser.DefaultTags = defaultTags
+	return parser, nil
+}
+
 func NewWavefrontParser(defaultTags map[string]string) (Parser, error) {
 	return wavefront.NewWavefrontParser(defaultTags), nil
 }
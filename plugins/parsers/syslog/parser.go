@@ -0,0 +1,443 @@
+package syslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/influxdata/go-syslog"
+	"github.com/influxdata/go-syslog/nontransparent"
+	"github.com/influxdata/go-syslog/octetcounting"
+	"github.com/influxdata/telegraf"
+	framing "github.com/influxdata/telegraf/internal/syslog"
+	"github.com/influxdata/telegraf/metric"
+)
+
+const (
+	RFC5424 = "rfc5424"
+	RFC3164 = "rfc3164"
+)
+
+var (
+	ErrNoMetric = errors.New("no metric in input")
+)
+
+var severityLevels = []string{
+	"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug",
+}
+
+var facilityLevels = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console",
+	"solaris-cron", "local0", "local1", "local2", "local3", "local4",
+	"local5", "local6", "local7",
+}
+
+// Parser creates metrics from syslog messages using the same schema as the
+// syslog input.
+type Parser struct {
+	Format      string
+	Framing     framing.Framing
+	BestEffort  bool
+	Separator   string
+	DefaultTags map[string]string
+	Now         func() time.Time
+
+	// mu guards lastTime, Parse can be called concurrently, for example by
+	// the http_listener_v2 input.
+	mu       sync.Mutex
+	lastTime time.Time
+}
+
+// NewParser creates a parser for messages in the RFC5424 or RFC3164 format.
+func NewParser(format string, f framing.Framing, bestEffort bool, separator string) (*Parser, error) {
+	p := &Parser{
+		Format:     format,
+		Framing:    f,
+		BestEffort: bestEffort,
+		Separator:  separator,
+		Now:        time.Now,
+	}
+
+	switch p.Format {
+	case "":
+		p.Format = RFC5424
+	case RFC5424, RFC3164:
+	default:
+		return nil, fmt.Errorf("unknown syslog format %q", format)
+	}
+
+	switch p.Framing {
+	case framing.OctetCounting, framing.NonTransparent:
+	default:
+		return nil, fmt.Errorf("unknown syslog framing %d", p.Framing)
+	}
+
+	if p.Separator == "" {
+		p.Separator = "_"
+	}
+	return p, nil
+}
+
+// Parse converts the framed syslog messages in buf to metrics.
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	if p.Format == RFC3164 {
+		return p.parseRFC3164(buf)
+	}
+	return p.parseRFC5424(buf)
+}
+
+// parseRFC5424 uses the go-syslog framing parsers, the same as the syslog
+// input, to parse the messages in buf.
+func (p *Parser) parseRFC5424(buf []byte) ([]telegraf.Metric, error) {
+	var metrics []telegraf.Metric
+	var err error
+	emit := func(r *syslog.Result) {
+		if err != nil {
+			return
+		}
+		if r.Error != nil && (r.Message == nil || !p.BestEffort) {
+			err = r.Error
+			return
+		}
+		if r.Message == nil {
+			return
+		}
+
+		var m telegraf.Metric
+		m, err = p.newMetric(MessageTags(r.Message), MessageFields(r.Message, p.Separator))
+		if err == nil {
+			metrics = append(metrics, m)
+		}
+	}
+
+	opts := []syslog.ParserOption{
+		syslog.WithListener(emit),
+	}
+	if p.BestEffort {
+		opts = append(opts, syslog.WithBestEffort())
+	}
+
+	var parser syslog.Parser
+	if p.Framing == framing.OctetCounting {
+		parser = octetcounting.NewParser(opts...)
+	} else {
+		opts = append(opts, nontransparent.WithTrailer(nontransparent.LF))
+		parser = nontransparent.NewParser(opts...)
+	}
+	parser.Parse(bytes.NewReader(buf))
+
+	if err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// parseRFC3164 splits buf into messages and parses each as an RFC3164
+// message.  The go-syslog framing parsers only produce RFC5424 messages, so
+// the framing is handled here.
+func (p *Parser) parseRFC3164(buf []byte) ([]telegraf.Metric, error) {
+	var messages [][]byte
+	var err error
+	if p.Framing == framing.OctetCounting {
+		messages, err = splitOctetCounting(buf)
+	} else {
+		messages = splitNonTransparent(buf)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := make([]telegraf.Metric, 0, len(messages))
+	for _, msg := range messages {
+		tags, fields, err := parseRFC3164(msg, p.now(), p.BestEffort)
+		if err != nil {
+			return nil, err
+		}
+		m, err := p.newMetric(tags, fields)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, nil
+}
+
+// ParseLine converts a single syslog message to a metric.
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) < 1 {
+		return nil, ErrNoMetric
+	}
+
+	return metrics[0], nil
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
+func (p *Parser) newMetric(tags map[string]string, fields map[string]interface{}) (telegraf.Metric, error) {
+	for k, v := range p.DefaultTags {
+		if _, ok := tags[k]; !ok {
+			tags[k] = v
+		}
+	}
+	return metric.New("syslog", tags, fields, p.time())
+}
+
+func (p *Parser) now() time.Time {
+	if p.Now == nil {
+		return time.Now()
+	}
+	return p.Now()
+}
+
+// time returns the current time, ensuring that each metric has a unique
+// timestamp.
+func (p *Parser) time() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t := p.now()
+	if t == p.lastTime {
+		t = t.Add(time.Nanosecond)
+	}
+	p.lastTime = t
+	return t
+}
+
+// splitNonTransparent returns each non empty line in buf.
+func splitNonTransparent(buf []byte) [][]byte {
+	var messages [][]byte
+	for _, line := range bytes.Split(buf, []byte("\n")) {
+		line = bytes.TrimRight(line, "\r")
+		if len(line) == 0 {
+			continue
+		}
+		messages = append(messages, line)
+	}
+	return messages
+}
+
+// splitOctetCounting returns each message in buf framed as described in
+// RFC6587, the message length followed by a space and the message.
+func splitOctetCounting(buf []byte) ([][]byte, error) {
+	var messages [][]byte
+	for {
+		buf = bytes.TrimLeftFunc(buf, unicode.IsSpace)
+		if len(buf) == 0 {
+			return messages, nil
+		}
+
+		i := bytes.IndexByte(buf, ' ')
+		if i <= 0 {
+			return nil, errors.New("expecting message length followed by a space")
+		}
+
+		n, err := strconv.Atoi(string(buf[:i]))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid message length %q", buf[:i])
+		}
+
+		buf = buf[i+1:]
+		if n > len(buf) {
+			return nil, fmt.Errorf("message length %d exceeds remaining %d bytes", n, len(buf))
+		}
+		messages = append(messages, buf[:n])
+		buf = buf[n:]
+	}
+}
+
+// MessageTags returns the tags for a parsed syslog message.  It is shared with
+// the syslog input so that both produce the same schema.
+func MessageTags(msg syslog.Message) map[string]string {
+	ts := map[string]string{}
+
+	// Not checking assuming a minimally valid message
+	ts["severity"] = *msg.SeverityShortLevel()
+	ts["facility"] = *msg.FacilityLevel()
+
+	if msg.Hostname() != nil {
+		ts["hostname"] = *msg.Hostname()
+	}
+
+	if msg.Appname() != nil {
+		ts["appname"] = *msg.Appname()
+	}
+
+	return ts
+}
+
+// MessageFields returns the fields for a parsed syslog message, structured
+// data parameters are named by joining the SD-ID and name with the separator.
+func MessageFields(msg syslog.Message, separator string) map[string]interface{} {
+	// Not checking assuming a minimally valid message
+	flds := map[string]interface{}{
+		"version": msg.Version(),
+	}
+	flds["severity_code"] = int(*msg.Severity())
+	flds["facility_code"] = int(*msg.Facility())
+
+	if msg.Timestamp() != nil {
+		flds["timestamp"] = (*msg.Timestamp()).UnixNano()
+	}
+
+	if msg.ProcID() != nil {
+		flds["procid"] = *msg.ProcID()
+	}
+
+	if msg.MsgID() != nil {
+		flds["msgid"] = *msg.MsgID()
+	}
+
+	if msg.Message() != nil {
+		flds["message"] = trimMessage(*msg.Message())
+	}
+
+	if msg.StructuredData() != nil {
+		for sdid, sdparams := range *msg.StructuredData() {
+			if len(sdparams) == 0 {
+				// When SD-ID does not have params we indicate its presence with a bool
+				flds[sdid] = true
+				continue
+			}
+			for name, value := range sdparams {
+				// Using whitespace as separator since it is not allowed by the grammar within SDID
+				flds[sdid+separator+name] = value
+			}
+		}
+	}
+
+	return flds
+}
+
+// parseRFC3164 parses a BSD syslog message of the form:
+//
+//	<PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MESSAGE
+//
+// An RFC3339 timestamp is also accepted in place of the BSD timestamp.  BSD
+// timestamps do not contain a year or time zone, they are assumed to be in
+// UTC and in the current year, or the previous year if that would place them
+// in the future.
+func parseRFC3164(msg []byte, now time.Time, bestEffort bool) (map[string]string, map[string]interface{}, error) {
+	s := string(msg)
+
+	prival, s, err := parsePriority(s)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	severity := prival % 8
+	facility := prival / 8
+	tags := map[string]string{
+		"severity": severityLevels[severity],
+		"facility": facilityLevels[facility],
+	}
+	fields := map[string]interface{}{
+		"severity_code": severity,
+		"facility_code": facility,
+	}
+
+	ts, s, ok := parseTimestamp(s, now)
+	if ok {
+		fields["timestamp"] = ts.UnixNano()
+	} else if !bestEffort {
+		return nil, nil, errors.New("expecting timestamp")
+	}
+
+	// The hostname is omitted by some senders, a first word that looks like
+	// a tag is not used as the hostname.
+	if i := strings.IndexByte(s, ' '); i > 0 && !strings.ContainsAny(s[:i], ":[") {
+		tags["hostname"] = s[:i]
+		s = s[i+1:]
+	}
+
+	if i := strings.IndexAny(s, ":[ "); i > 0 && s[i] != ' ' {
+		tags["appname"] = s[:i]
+		s = s[i:]
+		if s[0] == '[' {
+			if j := strings.IndexByte(s, ']'); j > 0 {
+				fields["procid"] = s[1:j]
+				s = s[j+1:]
+			}
+		}
+		s = strings.TrimPrefix(s, ":")
+		s = strings.TrimPrefix(s, " ")
+	}
+
+	if message := trimMessage(s); message != "" {
+		fields["message"] = message
+	}
+	return tags, fields, nil
+}
+
+// parsePriority parses the <PRI> part of a message and returns the priority
+// value and the remainder of the message.
+func parsePriority(s string) (int, string, error) {
+	if !strings.HasPrefix(s, "<") {
+		return 0, "", errors.New("expecting priority")
+	}
+
+	end := strings.IndexByte(s, '>')
+	if end < 2 || end > 4 {
+		return 0, "", errors.New("expecting priority value between 1 and 3 digits")
+	}
+
+	prival, err := strconv.Atoi(s[1:end])
+	if err != nil || prival < 0 || prival > 191 {
+		return 0, "", fmt.Errorf("invalid priority value %q", s[1:end])
+	}
+	return prival, s[end+1:], nil
+}
+
+// parseTimestamp parses a BSD or RFC3339 timestamp followed by a space and
+// returns the time and the remainder of the message.
+func parseTimestamp(s string, now time.Time) (time.Time, string, bool) {
+	if len(s) > len(time.Stamp) && s[len(time.Stamp)] == ' ' {
+		ts, err := time.Parse(time.Stamp, s[:len(time.Stamp)])
+		if err == nil {
+			return stampTime(ts, now), s[len(time.Stamp)+1:], true
+		}
+	}
+
+	if i := strings.IndexByte(s, ' '); i > 0 {
+		ts, err := time.Parse(time.RFC3339Nano, s[:i])
+		if err == nil {
+			return ts, s[i+1:], true
+		}
+	}
+	return time.Time{}, s, false
+}
+
+// stampTime sets the year of a BSD timestamp.  A message from late December
+// that is received in January is from the previous year; a day of clock skew
+// is allowed before a timestamp is considered to be in the future.
+func stampTime(ts time.Time, now time.Time) time.Time {
+	now = now.UTC()
+	year := now.Year()
+	if stampDate(ts, year).After(now.Add(24 * time.Hour)) {
+		year--
+	}
+	return stampDate(ts, year)
+}
+
+func stampDate(ts time.Time, year int) time.Time {
+	return time.Date(year, ts.Month(), ts.Day(),
+		ts.Hour(), ts.Minute(), ts.Second(), ts.Nanosecond(), time.UTC)
+}
+
+func trimMessage(s string) string {
+	return strings.TrimRightFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r)
+	})
+}
@@ -0,0 +1,389 @@
+package syslog
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	framing "github.com/influxdata/telegraf/internal/syslog"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+var defaultTime = time.Unix(1555000000, 0)
+
+func TestParseRFC3164(t *testing.T) {
+	var tests = []struct {
+		name       string
+		input      string
+		bestEffort bool
+		expected   []telegraf.Metric
+		err        bool
+	}{
+		{
+			name:  "complete",
+			input: "<34>Oct 11 22:14:15 mymachine su[1234]: 'su root' failed for lonvick on /dev/pts/8\n",
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"syslog",
+					map[string]string{
+						"severity": "crit",
+						"facility": "auth",
+						"hostname": "mymachine",
+						"appname":  "su",
+					},
+					map[string]interface{}{
+						"severity_code": 2,
+						"facility_code": 4,
+						"timestamp":     time.Date(2018, time.October, 11, 22, 14, 15, 0, time.UTC).UnixNano(),
+						"procid":        "1234",
+						"message":       "'su root' failed for lonvick on /dev/pts/8",
+					},
+					defaultTime,
+				),
+			},
+		},
+		{
+			name:  "single digit day without pid",
+			input: "<13>Feb  5 17:32:18 10.0.0.99 myapp: Use the BFG!",
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"syslog",
+					map[string]string{
+						"severity": "notice",
+						"facility": "user",
+						"hostname": "10.0.0.99",
+						"appname":  "myapp",
+					},
+					map[string]interface{}{
+						"severity_code": 5,
+						"facility_code": 1,
+						"timestamp":     time.Date(2019, time.February, 5, 17, 32, 18, 0, time.UTC).UnixNano(),
+						"message":       "Use the BFG!",
+					},
+					defaultTime,
+				),
+			},
+		},
+		{
+			name:  "rfc3339 timestamp without hostname",
+			input: "<190>2019-04-11T16:26:40.5Z nginx: GET /index.html",
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"syslog",
+					map[string]string{
+						"severity": "info",
+						"facility": "local7",
+						"appname":  "nginx",
+					},
+					map[string]interface{}{
+						"severity_code": 6,
+						"facility_code": 23,
+						"timestamp":     time.Date(2019, time.April, 11, 16, 26, 40, 500000000, time.UTC).UnixNano(),
+						"message":       "GET /index.html",
+					},
+					defaultTime,
+				),
+			},
+		},
+		{
+			name:  "without tag",
+			input: "<0>Oct 11 22:14:15 mymachine kernel panic",
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"syslog",
+					map[string]string{
+						"severity": "emerg",
+						"facility": "kern",
+						"hostname": "mymachine",
+					},
+					map[string]interface{}{
+						"severity_code": 0,
+						"facility_code": 0,
+						"timestamp":     time.Date(2018, time.October, 11, 22, 14, 15, 0, time.UTC).UnixNano(),
+						"message":       "kernel panic",
+					},
+					defaultTime,
+				),
+			},
+		},
+		{
+			name:  "missing timestamp",
+			input: "<13>mymachine myapp: message",
+			err:   true,
+		},
+		{
+			name:       "missing timestamp best effort",
+			input:      "<13>mymachine myapp: message",
+			bestEffort: true,
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"syslog",
+					map[string]string{
+						"severity": "notice",
+						"facility": "user",
+						"hostname": "mymachine",
+						"appname":  "myapp",
+					},
+					map[string]interface{}{
+						"severity_code": 5,
+						"facility_code": 1,
+						"message":       "message",
+					},
+					defaultTime,
+				),
+			},
+		},
+		{
+			name:  "missing priority",
+			input: "Oct 11 22:14:15 mymachine su: message",
+			err:   true,
+		},
+		{
+			name:  "invalid priority",
+			input: "<192>Oct 11 22:14:15 mymachine su: message",
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, err := NewParser(RFC3164, framing.NonTransparent, tt.bestEffort, "")
+			require.NoError(t, err)
+			parser.Now = func() time.Time { return defaultTime }
+
+			actual, err := parser.Parse([]byte(tt.input))
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestParseRFC5424(t *testing.T) {
+	parser, err := NewParser(RFC5424, framing.NonTransparent, false, "_")
+	require.NoError(t, err)
+	parser.Now = func() time.Time { return defaultTime }
+
+	actual, err := parser.ParseLine(`<29>1 2016-02-21T04:32:57+00:00 web1 someservice 2341 2 [origin][meta sequence="14125553" service="someservice"] "GET /v1/ok HTTP/1.1" 200 145 "-" "hacheck 0.9.0" 24306 127.0.0.1:40124 575`)
+	require.NoError(t, err)
+
+	expected := testutil.MustMetric(
+		"syslog",
+		map[string]string{
+			"severity": "notice",
+			"facility": "daemon",
+			"hostname": "web1",
+			"appname":  "someservice",
+		},
+		map[string]interface{}{
+			"version":       uint16(1),
+			"timestamp":     time.Unix(1456029177, 0).UnixNano(),
+			"procid":        "2341",
+			"msgid":         "2",
+			"message":       `"GET /v1/ok HTTP/1.1" 200 145 "-" "hacheck 0.9.0" 24306 127.0.0.1:40124 575`,
+			"origin":        true,
+			"meta_sequence": "14125553",
+			"meta_service":  "someservice",
+			"severity_code": 5,
+			"facility_code": 3,
+		},
+		defaultTime,
+	)
+	testutil.RequireMetricEqual(t, expected, actual)
+}
+
+func TestParseRFC5424Invalid(t *testing.T) {
+	parser, err := NewParser(RFC5424, framing.NonTransparent, false, "_")
+	require.NoError(t, err)
+
+	_, err = parser.Parse([]byte("<1>1 - - - - - X\n"))
+	require.Error(t, err)
+}
+
+func TestParseRFC5424Framing(t *testing.T) {
+	var tests = []struct {
+		name    string
+		framing framing.Framing
+		input   string
+	}{
+		{
+			name:    "non-transparent",
+			framing: framing.NonTransparent,
+			input:   "<13>1 - - - - - - one\n<13>1 - - - - - - two\n",
+		},
+		{
+			name:    "octet counting",
+			framing: framing.OctetCounting,
+			input:   "21 <13>1 - - - - - - one21 <13>1 - - - - - - two",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, err := NewParser(RFC5424, tt.framing, false, "")
+			require.NoError(t, err)
+
+			metrics, err := parser.Parse([]byte(tt.input))
+			require.NoError(t, err)
+			require.Len(t, metrics, 2)
+
+			var messages []string
+			for _, m := range metrics {
+				message, _ := m.GetField("message")
+				messages = append(messages, message.(string))
+			}
+			require.Equal(t, []string{"one", "two"}, messages)
+		})
+	}
+}
+
+func TestParseFraming(t *testing.T) {
+	var tests = []struct {
+		name     string
+		framing  framing.Framing
+		input    string
+		messages []string
+		err      bool
+	}{
+		{
+			name:     "non-transparent",
+			framing:  framing.NonTransparent,
+			input:    "<13>Oct 11 22:14:15 host a: one\r\n\n<13>Oct 11 22:14:15 host a: two",
+			messages: []string{"one", "two"},
+		},
+		{
+			name:     "octet counting",
+			framing:  framing.OctetCounting,
+			input:    "29 <13>Oct 11 22:14:15 host a: 1\n30 <13>Oct 11 22:14:15 host a: 22",
+			messages: []string{"1", "22"},
+		},
+		{
+			name:     "octet counting message with newline",
+			framing:  framing.OctetCounting,
+			input:    "31 <13>Oct 11 22:14:15 host a: a\nb",
+			messages: []string{"a\nb"},
+		},
+		{
+			name:    "octet counting short message",
+			framing: framing.OctetCounting,
+			input:   "40 <13>Oct 11 22:14:15 host a: 1",
+			err:     true,
+		},
+		{
+			name:    "octet counting missing length",
+			framing: framing.OctetCounting,
+			input:   "<13>Oct 11 22:14:15 host a: 1",
+			err:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, err := NewParser(RFC3164, tt.framing, false, "")
+			require.NoError(t, err)
+
+			metrics, err := parser.Parse([]byte(tt.input))
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var messages []string
+			for _, m := range metrics {
+				message, _ := m.GetField("message")
+				messages = append(messages, message.(string))
+			}
+			require.Equal(t, tt.messages, messages)
+		})
+	}
+}
+
+func TestStampTime(t *testing.T) {
+	var tests = []struct {
+		name     string
+		now      time.Time
+		stamp    string
+		expected time.Time
+	}{
+		{
+			name:     "current year",
+			now:      time.Date(2019, time.April, 11, 0, 0, 0, 0, time.UTC),
+			stamp:    "Feb  5 17:32:18",
+			expected: time.Date(2019, time.February, 5, 17, 32, 18, 0, time.UTC),
+		},
+		{
+			name:     "previous year",
+			now:      time.Date(2019, time.January, 1, 0, 10, 0, 0, time.UTC),
+			stamp:    "Dec 31 23:59:58",
+			expected: time.Date(2018, time.December, 31, 23, 59, 58, 0, time.UTC),
+		},
+		{
+			name:     "clock skew",
+			now:      time.Date(2019, time.April, 11, 0, 0, 0, 0, time.UTC),
+			stamp:    "Apr 11 00:05:00",
+			expected: time.Date(2019, time.April, 11, 0, 5, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts, err := time.Parse(time.Stamp, tt.stamp)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, stampTime(ts, tt.now))
+		})
+	}
+}
+
+func TestUniqueTime(t *testing.T) {
+	parser, err := NewParser(RFC3164, framing.NonTransparent, false, "")
+	require.NoError(t, err)
+	parser.Now = func() time.Time { return defaultTime }
+
+	metrics, err := parser.Parse([]byte("<13>Oct 11 22:14:15 host a: 1\n<13>Oct 11 22:14:15 host a: 2\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+	require.Equal(t, defaultTime, metrics[0].Time())
+	require.Equal(t, defaultTime.Add(time.Nanosecond), metrics[1].Time())
+}
+
+func TestParseConcurrent(t *testing.T) {
+	parser, err := NewParser(RFC3164, framing.NonTransparent, false, "")
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				metrics, err := parser.Parse([]byte("<13>Oct 11 22:14:15 host a: 1\n"))
+				require.NoError(t, err)
+				require.Len(t, metrics, 1)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDefaultTags(t *testing.T) {
+	parser, err := NewParser(RFC3164, framing.NonTransparent, false, "")
+	require.NoError(t, err)
+	parser.SetDefaultTags(map[string]string{"hostname": "default", "source": "tail"})
+
+	m, err := parser.ParseLine("<13>Oct 11 22:14:15 host a: 1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"severity": "notice",
+		"facility": "user",
+		"hostname": "host",
+		"appname":  "a",
+		"source":   "tail",
+	}, m.Tags())
+}
+
+func TestInvalidFormat(t *testing.T) {
+	_, err := NewParser("rfc1234", framing.NonTransparent, false, "")
+	require.Error(t, err)
+}
@@ -0,0 +1,215 @@
+package cardinality
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+const sampleConfig = `
+  ## Maximum number of distinct series for each measurement seen within the
+  ## window.
+  limit = 10000
+
+  ## Series not seen for this long no longer count towards the limit.
+  # window = "1h"
+
+  ## Action to take on metrics of new series once the limit is reached:
+  ##   drop       - drop the metric
+  ##   strip_tags - remove the configured tags
+  ##   collapse   - replace the value of the configured tags with collapse_value
+  ## If the metric is still a new series after removing or replacing tags it
+  ## is dropped.
+  # action = "drop"
+
+  ## Tags to remove or replace with the strip_tags and collapse actions.
+  # tags = []
+
+  ## Tag value used by the collapse action.
+  # collapse_value = "other"
+`
+
+const (
+	actionDrop      = "drop"
+	actionStripTags = "strip_tags"
+	actionCollapse  = "collapse"
+)
+
+// instances numbers the plugin instances so that each has its own statistics.
+var instances int64
+
+type Cardinality struct {
+	Limit         int               `toml:"limit"`
+	Window        internal.Duration `toml:"window"`
+	Action        string            `toml:"action"`
+	Tags          []string          `toml:"tags"`
+	CollapseValue string            `toml:"collapse_value"`
+
+	measurements map[string]*measurement
+	lastExpire   time.Time
+
+	metricsDropped  selfstat.Stat
+	metricsModified selfstat.Stat
+}
+
+// measurement holds the time each series of a measurement was last seen.
+type measurement struct {
+	series  map[uint64]time.Time
+	limited bool
+}
+
+func New() *Cardinality {
+	return &Cardinality{
+		Limit:         10000,
+		Window:        internal.Duration{Duration: time.Hour},
+		Action:        actionDrop,
+		CollapseValue: "other",
+	}
+}
+
+func (c *Cardinality) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *Cardinality) Description() string {
+	return "Limit the number of series of each measurement."
+}
+
+func (c *Cardinality) Init() error {
+	if c.Limit <= 0 {
+		return fmt.Errorf("limit must be positive")
+	}
+
+	if c.Window.Duration <= 0 {
+		return fmt.Errorf("window must be positive")
+	}
+
+	switch c.Action {
+	case actionDrop:
+	case actionStripTags, actionCollapse:
+		if len(c.Tags) == 0 {
+			return fmt.Errorf("action %q requires tags", c.Action)
+		}
+	default:
+		return fmt.Errorf("unknown action %q", c.Action)
+	}
+
+	c.measurements = make(map[string]*measurement)
+	c.lastExpire = time.Now()
+
+	tags := map[string]string{
+		"instance": strconv.FormatInt(atomic.AddInt64(&instances, 1)-1, 10),
+	}
+	c.metricsDropped = selfstat.Register("cardinality", "metrics_dropped", tags)
+	c.metricsModified = selfstat.Register("cardinality", "metrics_modified", tags)
+	return nil
+}
+
+func (c *Cardinality) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := time.Now()
+	c.expire(now)
+
+	out := in[:0]
+	for _, m := range in {
+		if c.accept(m, now) {
+			out = append(out, m)
+			continue
+		}
+		c.metricsDropped.Incr(1)
+		m.Drop()
+	}
+	return out
+}
+
+// accept records the series of the metric and returns true if it should be
+// emitted, the tags of the metric are modified if the limit is reached.
+func (c *Cardinality) accept(m telegraf.Metric, now time.Time) bool {
+	meas, ok := c.measurements[m.Name()]
+	if !ok {
+		meas = &measurement{series: make(map[uint64]time.Time)}
+		c.measurements[m.Name()] = meas
+	}
+
+	if meas.add(m.HashID(), now, c.Limit) {
+		return true
+	}
+
+	if !meas.limited {
+		meas.limited = true
+		log.Printf("W! [processors.cardinality] series limit of %d reached for measurement %q", c.Limit, m.Name())
+	}
+
+	modified := false
+	switch c.Action {
+	case actionStripTags:
+		for _, key := range c.Tags {
+			if m.HasTag(key) {
+				m.RemoveTag(key)
+				modified = true
+			}
+		}
+	case actionCollapse:
+		for _, key := range c.Tags {
+			if value, ok := m.GetTag(key); ok && value != c.CollapseValue {
+				m.AddTag(key, c.CollapseValue)
+				modified = true
+			}
+		}
+	}
+	if !modified {
+		return false
+	}
+
+	c.metricsModified.Incr(1)
+	return meas.add(m.HashID(), now, c.Limit)
+}
+
+// add records the series and returns true if it is a known series or if the
+// limit has not been reached.
+func (meas *measurement) add(id uint64, now time.Time, limit int) bool {
+	if _, ok := meas.series[id]; !ok && len(meas.series) >= limit {
+		return false
+	}
+	meas.series[id] = now
+	return true
+}
+
+// expire removes series that were not seen within the window, this is done
+// at most ten times per window.
+func (c *Cardinality) expire(now time.Time) {
+	if now.Sub(c.lastExpire) < c.Window.Duration/10 {
+		return
+	}
+	c.lastExpire = now
+
+	for name, meas := range c.measurements {
+		for id, lastSeen := range meas.series {
+			if now.Sub(lastSeen) >= c.Window.Duration {
+				delete(meas.series, id)
+			}
+		}
+
+		if len(meas.series) == 0 {
+			delete(c.measurements, name)
+			continue
+		}
+
+		if meas.limited && len(meas.series) < c.Limit {
+			meas.limited = false
+			log.Printf("I! [processors.cardinality] measurement %q is below the series limit of %d", name, c.Limit)
+		}
+	}
+}
+
+func init() {
+	processors.Add("cardinality", func() telegraf.Processor {
+		return New()
+	})
+}
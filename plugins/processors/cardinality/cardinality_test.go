@@ -0,0 +1,174 @@
+package cardinality
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func requestMetric(id string) telegraf.Metric {
+	return testutil.MustMetric(
+		"statsd",
+		map[string]string{"host": "a", "request_id": id},
+		map[string]interface{}{"value": 1},
+		time.Unix(0, 0),
+	)
+}
+
+func TestDrop(t *testing.T) {
+	plugin := New()
+	plugin.Limit = 2
+	require.NoError(t, plugin.Init())
+
+	dropped := plugin.metricsDropped.Get()
+
+	actual := plugin.Apply(
+		requestMetric("1"),
+		requestMetric("2"),
+		requestMetric("3"),
+		requestMetric("1"),
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+	)
+
+	expected := []telegraf.Metric{
+		requestMetric("1"),
+		requestMetric("2"),
+		requestMetric("1"),
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+	require.Equal(t, int64(1), plugin.metricsDropped.Get()-dropped)
+}
+
+func TestStripTags(t *testing.T) {
+	plugin := New()
+	plugin.Limit = 2
+	plugin.Action = "strip_tags"
+	plugin.Tags = []string{"request_id"}
+	require.NoError(t, plugin.Init())
+
+	modified := plugin.metricsModified.Get()
+	dropped := plugin.metricsDropped.Get()
+
+	actual := plugin.Apply(
+		requestMetric("1"),
+		requestMetric("2"),
+		requestMetric("3"),
+		requestMetric("4"),
+	)
+
+	// The stripped series is a new series, it is only accepted after an
+	// existing series expires.
+	expected := []telegraf.Metric{
+		requestMetric("1"),
+		requestMetric("2"),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+	require.Equal(t, int64(2), plugin.metricsModified.Get()-modified)
+	require.Equal(t, int64(2), plugin.metricsDropped.Get()-dropped)
+}
+
+func TestCollapse(t *testing.T) {
+	plugin := New()
+	plugin.Limit = 3
+	plugin.Action = "collapse"
+	plugin.Tags = []string{"request_id"}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(
+		requestMetric("1"),
+		requestMetric("2"),
+		requestMetric("3"),
+		requestMetric("4"),
+		requestMetric("5"),
+	)
+
+	expected := []telegraf.Metric{
+		requestMetric("1"),
+		requestMetric("2"),
+		requestMetric("3"),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// Once the collapsed series is known it is accepted.
+	plugin = New()
+	plugin.Limit = 3
+	plugin.Action = "collapse"
+	plugin.Tags = []string{"request_id"}
+	require.NoError(t, plugin.Init())
+
+	actual = plugin.Apply(
+		requestMetric("1"),
+		requestMetric("other"),
+		requestMetric("2"),
+		requestMetric("3"),
+		requestMetric("4"),
+	)
+
+	expected = []telegraf.Metric{
+		requestMetric("1"),
+		requestMetric("other"),
+		requestMetric("2"),
+		requestMetric("other"),
+		requestMetric("other"),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestWindow(t *testing.T) {
+	plugin := New()
+	plugin.Limit = 1
+	plugin.Window.Duration = time.Minute
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(requestMetric("1"), requestMetric("2"))
+	require.Len(t, actual, 1)
+
+	// Age the known series past the window.
+	past := time.Now().Add(-time.Hour)
+	for _, meas := range plugin.measurements {
+		for id := range meas.series {
+			meas.series[id] = past
+		}
+	}
+	plugin.lastExpire = past
+
+	actual = plugin.Apply(requestMetric("2"))
+	require.Len(t, actual, 1)
+}
+
+func TestStatsPerInstance(t *testing.T) {
+	plugin1 := New()
+	plugin1.Limit = 1
+	require.NoError(t, plugin1.Init())
+	plugin2 := New()
+	plugin2.Limit = 1
+	require.NoError(t, plugin2.Init())
+
+	dropped := plugin2.metricsDropped.Get()
+	plugin1.Apply(requestMetric("1"), requestMetric("2"))
+
+	require.NotEqual(t, plugin1.metricsDropped.Tags()["instance"], plugin2.metricsDropped.Tags()["instance"])
+	require.Equal(t, dropped, plugin2.metricsDropped.Get())
+}
+
+func TestInit(t *testing.T) {
+	plugin := New()
+	plugin.Limit = 0
+	require.Error(t, plugin.Init())
+
+	plugin = New()
+	plugin.Action = "unknown"
+	require.Error(t, plugin.Init())
+
+	plugin = New()
+	plugin.Action = "collapse"
+	require.Error(t, plugin.Init())
+
+	plugin = New()
+	plugin.Window.Duration = 0
+	require.Error(t, plugin.Init())
+}
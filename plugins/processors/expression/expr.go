@@ -0,0 +1,589 @@
+package expression
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Expressions operate on int64, float64, string and bool values.  Fields are
+// referenced by name, or with the field function for names that are not
+// identifiers, and tags with the tag function.
+//
+// Arithmetic on two integers results in an integer except for division, which
+// always results in a float.
+
+// node is an element of a parsed expression.
+type node interface {
+	eval(m telegraf.Metric) (interface{}, error)
+}
+
+type literal struct {
+	value interface{}
+}
+
+func (n *literal) eval(m telegraf.Metric) (interface{}, error) {
+	return n.value, nil
+}
+
+type fieldRef struct {
+	name string
+}
+
+func (n *fieldRef) eval(m telegraf.Metric) (interface{}, error) {
+	return getField(m, n.name)
+}
+
+// missingError is returned when a field or tag used by an expression is not
+// present in the metric.
+type missingError struct {
+	kind string
+	key  string
+}
+
+func (e *missingError) Error() string {
+	return fmt.Sprintf("%s %q not found", e.kind, e.key)
+}
+
+func getField(m telegraf.Metric, name string) (interface{}, error) {
+	value, ok := m.GetField(name)
+	if !ok {
+		return nil, &missingError{kind: "field", key: name}
+	}
+
+	switch v := value.(type) {
+	case uint64:
+		if v > math.MaxInt64 {
+			return float64(v), nil
+		}
+		return int64(v), nil
+	case int64, float64, string, bool:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("field %q has unsupported type %T", name, value)
+	}
+}
+
+type unary struct {
+	op string
+	x  node
+}
+
+func (n *unary) eval(m telegraf.Metric) (interface{}, error) {
+	x, err := n.x.eval(m)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "-":
+		switch v := x.(type) {
+		case int64:
+			return -v, nil
+		case float64:
+			return -v, nil
+		}
+	case "!":
+		if v, ok := x.(bool); ok {
+			return !v, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid operand for %s: %s", n.op, typeName(x))
+}
+
+type binary struct {
+	op   string
+	l, r node
+}
+
+func (n *binary) eval(m telegraf.Metric) (interface{}, error) {
+	l, err := n.l.eval(m)
+	if err != nil {
+		return nil, err
+	}
+
+	// The logical operators only evaluate the right operand if needed.
+	if n.op == "&&" || n.op == "||" {
+		lb, ok := l.(bool)
+		if !ok {
+			return nil, fmt.Errorf("invalid operand for %s: %s", n.op, typeName(l))
+		}
+		if (n.op == "&&" && !lb) || (n.op == "||" && lb) {
+			return lb, nil
+		}
+		r, err := n.r.eval(m)
+		if err != nil {
+			return nil, err
+		}
+		rb, ok := r.(bool)
+		if !ok {
+			return nil, fmt.Errorf("invalid operand for %s: %s", n.op, typeName(r))
+		}
+		return rb, nil
+	}
+
+	r, err := n.r.eval(m)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		return compare(n.op, l, r)
+	default:
+		return arithmetic(n.op, l, r)
+	}
+}
+
+func arithmetic(op string, l, r interface{}) (interface{}, error) {
+	if ls, ok := l.(string); ok && op == "+" {
+		if rs, ok := r.(string); ok {
+			return ls + rs, nil
+		}
+	}
+
+	li, lint := l.(int64)
+	ri, rint := r.(int64)
+	if lint && rint && op != "/" {
+		switch op {
+		case "+":
+			return li + ri, nil
+		case "-":
+			return li - ri, nil
+		case "*":
+			return li * ri, nil
+		case "%":
+			if ri == 0 {
+				return nil, fmt.Errorf("integer modulo by zero")
+			}
+			return li % ri, nil
+		}
+	}
+
+	lf, lok := toNumber(l)
+	rf, rok := toNumber(r)
+	if !lok || !rok {
+		return nil, fmt.Errorf("invalid operands for %s: %s and %s", op, typeName(l), typeName(r))
+	}
+
+	switch op {
+	case "+":
+		return lf + rf, nil
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	case "/":
+		return lf / rf, nil
+	case "%":
+		return math.Mod(lf, rf), nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", op)
+}
+
+func compare(op string, l, r interface{}) (interface{}, error) {
+	var c int
+	switch lv := l.(type) {
+	case string:
+		rv, ok := r.(string)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare %s and %s", typeName(l), typeName(r))
+		}
+		c = strings.Compare(lv, rv)
+	case bool:
+		rv, ok := r.(bool)
+		if !ok || (op != "==" && op != "!=") {
+			return nil, fmt.Errorf("cannot compare %s and %s with %s", typeName(l), typeName(r), op)
+		}
+		if lv != rv {
+			c = 1
+		}
+	default:
+		lf, lok := toNumber(l)
+		rf, rok := toNumber(r)
+		if !lok || !rok {
+			return nil, fmt.Errorf("cannot compare %s and %s", typeName(l), typeName(r))
+		}
+		switch {
+		case lf < rf:
+			c = -1
+		case lf > rf:
+			c = 1
+		}
+	}
+
+	switch op {
+	case "==":
+		return c == 0, nil
+	case "!=":
+		return c != 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default:
+		return c >= 0, nil
+	}
+}
+
+// conditional evaluates to a if the condition is true and b otherwise, only
+// the selected operand is evaluated.
+type conditional struct {
+	cond node
+	a, b node
+}
+
+func (n *conditional) eval(m telegraf.Metric) (interface{}, error) {
+	c, err := n.cond.eval(m)
+	if err != nil {
+		return nil, err
+	}
+
+	cb, ok := c.(bool)
+	if !ok {
+		return nil, fmt.Errorf("condition must be a boolean, got %s", typeName(c))
+	}
+	if cb {
+		return n.a.eval(m)
+	}
+	return n.b.eval(m)
+}
+
+type call struct {
+	name string
+	fn   function
+	args []node
+}
+
+func (n *call) eval(m telegraf.Metric) (interface{}, error) {
+	args := make([]interface{}, 0, len(n.args))
+	for _, arg := range n.args {
+		v, err := arg.eval(m)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+
+	v, err := n.fn.call(m, args)
+	if _, ok := err.(*missingError); ok {
+		return nil, err
+	} else if err != nil {
+		return nil, fmt.Errorf("%s: %v", n.name, err)
+	}
+	return v, nil
+}
+
+func toNumber(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case int64:
+		return "integer"
+	case float64:
+		return "float"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// Parse
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOperator
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value interface{}
+	pos   int
+}
+
+// operators are ordered so that longer operators are matched first.
+var operators = []string{
+	"==", "!=", "<=", ">=", "&&", "||",
+	"+", "-", "*", "/", "%", "<", ">", "!", "?", ":", "(", ")", ",",
+}
+
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(s) {
+		c := rune(s[i])
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c >= '0' && c <= '9' || c == '.':
+			start := i
+			for i < len(s) && (isDigit(s[i]) || s[i] == '.' || s[i] == 'e' || s[i] == 'E' ||
+				((s[i] == '+' || s[i] == '-') && (s[i-1] == 'e' || s[i-1] == 'E'))) {
+				i++
+			}
+			text := s[start:i]
+			var value interface{}
+			if v, err := strconv.ParseInt(text, 10, 64); err == nil {
+				value = v
+			} else if v, err := strconv.ParseFloat(text, 64); err == nil {
+				value = v
+			} else {
+				return nil, fmt.Errorf("invalid number %q at position %d", text, start)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, value: value, pos: start})
+		case c == '"' || c == '\'':
+			start := i
+			i++
+			var sb strings.Builder
+			for i < len(s) && rune(s[i]) != c {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				sb.WriteByte(s[i])
+				i++
+			}
+			if i >= len(s) {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			i++
+			tokens = append(tokens, token{kind: tokenString, text: s[start:i], value: sb.String(), pos: start})
+		case c == '_' || isLetter(s[i]):
+			start := i
+			for i < len(s) && (s[i] == '_' || isDigit(s[i]) || isLetter(s[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: s[start:i], pos: start})
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(s[i:], op) {
+					tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(s)}), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// precedence of the binary operators, higher binds tighter.
+var precedence = map[string]int{
+	"?":  1,
+	"||": 2,
+	"&&": 3,
+	"==": 4, "!=": 4,
+	"<": 5, "<=": 5, ">": 5, ">=": 5,
+	"+": 6, "-": 6,
+	"*": 7, "/": 7, "%": 7,
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parse parses an expression into a tree of nodes.
+func parse(s string) (node, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	n, err := p.parseExpr(1)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(op string) error {
+	t := p.next()
+	if t.kind != tokenOperator || t.text != op {
+		if t.kind == tokenEOF {
+			return fmt.Errorf("expected %q at end of expression", op)
+		}
+		return fmt.Errorf("expected %q at position %d, got %q", op, t.pos, t.text)
+	}
+	return nil
+}
+
+// parseExpr parses binary operators with at least the given precedence.
+func (p *parser) parseExpr(minPrec int) (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+		prec, ok := precedence[t.text]
+		if t.kind != tokenOperator || !ok || prec < minPrec {
+			return left, nil
+		}
+		p.next()
+
+		// The conditional operator is right associative.
+		if t.text == "?" {
+			a, err := p.parseExpr(1)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			b, err := p.parseExpr(1)
+			if err != nil {
+				return nil, err
+			}
+			left = &conditional{cond: left, a: a, b: b}
+			continue
+		}
+
+		right, err := p.parseExpr(prec + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{op: t.text, l: left, r: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	t := p.peek()
+	if t.kind == tokenOperator && (t.text == "-" || t.text == "!") {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unary{op: t.text, x: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber, tokenString:
+		return &literal{value: t.value}, nil
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return &literal{value: true}, nil
+		case "false":
+			return &literal{value: false}, nil
+		}
+		if next := p.peek(); next.kind == tokenOperator && next.text == "(" {
+			return p.parseCall(t)
+		}
+		return &fieldRef{name: t.text}, nil
+	case tokenOperator:
+		if t.text == "(" {
+			n, err := p.parseExpr(1)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return n, nil
+		}
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+	default:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	p.next()
+
+	var args []node
+	if t := p.peek(); t.kind == tokenOperator && t.text == ")" {
+		p.next()
+	} else {
+		for {
+			arg, err := p.parseExpr(1)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+
+			t := p.next()
+			if t.kind == tokenOperator && t.text == ")" {
+				break
+			}
+			if t.kind != tokenOperator || t.text != "," {
+				return nil, fmt.Errorf("expected \",\" or \")\" at position %d", t.pos)
+			}
+		}
+	}
+
+	// if is a conditional so that only the selected argument is evaluated.
+	if name.text == "if" {
+		if len(args) != 3 {
+			return nil, fmt.Errorf("if expects 3 arguments, got %d", len(args))
+		}
+		return &conditional{cond: args[0], a: args[1], b: args[2]}, nil
+	}
+
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at position %d", name.text, name.pos)
+	}
+	if fn.args >= 0 && len(args) != fn.args {
+		return nil, fmt.Errorf("%s expects %d arguments, got %d", name.text, fn.args, len(args))
+	}
+	if fn.args < 0 && len(args) == 0 {
+		return nil, fmt.Errorf("%s expects at least 1 argument", name.text)
+	}
+	return &call{name: name.text, fn: fn, args: args}, nil
+}
@@ -0,0 +1,144 @@
+package expression
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEval(t *testing.T) {
+	m := testutil.MustMetric(
+		"mem",
+		map[string]string{"host": "server01", "env": "prod"},
+		map[string]interface{}{
+			"used":      int64(25),
+			"total":     int64(100),
+			"free":      75.5,
+			"counter":   uint64(7),
+			"state":     "ok",
+			"available": true,
+			"field-x":   int64(3),
+		},
+		time.Unix(0, 0),
+	)
+
+	var tests = []struct {
+		expression string
+		expected   interface{}
+	}{
+		{expression: "used / total * 100", expected: 25.0},
+		{expression: "used + total", expected: int64(125)},
+		{expression: "used - total * 2", expected: int64(-175)},
+		{expression: "(used - total) * 2", expected: int64(-150)},
+		{expression: "total % 7", expected: int64(2)},
+		{expression: "free + 0.5", expected: 76.0},
+		{expression: "-used", expected: int64(-25)},
+		{expression: "counter * 2", expected: int64(14)},
+		{expression: "1.5e2", expected: 150.0},
+		{expression: "used > 10 && free < 100", expected: true},
+		{expression: "used < 10 && missing > 0", expected: false},
+		{expression: "used > 10 || missing > 0", expected: true},
+		{expression: "!available", expected: false},
+		{expression: "state == 'ok'", expected: true},
+		{expression: `state != "ok"`, expected: false},
+		{expression: "used == 25.0", expected: true},
+		{expression: "used >= 25 ? 'high' : 'low'", expected: "high"},
+		{expression: "used > 50 ? 'high' : used > 20 ? 'medium' : 'low'", expected: "medium"},
+		{expression: "if(used > 50, missing, 'ok')", expected: "ok"},
+		{expression: "state + '-' + tag('env')", expected: "ok-prod"},
+		{expression: "name()", expected: "mem"},
+		{expression: "field('field-x') * 2", expected: int64(6)},
+		{expression: "exists('missing')", expected: false},
+		{expression: "abs(-3.5)", expected: 3.5},
+		{expression: "round(2.5)", expected: 3.0},
+		{expression: "round(-2.5)", expected: -3.0},
+		{expression: "floor(free)", expected: 75.0},
+		{expression: "ceil(free)", expected: 76.0},
+		{expression: "sqrt(16)", expected: 4.0},
+		{expression: "pow(2, 10)", expected: 1024.0},
+		{expression: "log10(1000)", expected: 3.0},
+		{expression: "min(used, free, 30)", expected: int64(25)},
+		{expression: "max(used, free, 30)", expected: 75.5},
+		{expression: "int(free)", expected: int64(75)},
+		{expression: "int('42')", expected: int64(42)},
+		{expression: "int(available)", expected: int64(1)},
+		{expression: "float(used)", expected: 25.0},
+		{expression: "string(used)", expected: "25"},
+		{expression: "bool('true')", expected: true},
+		{expression: "used / (total + 1) > 0", expected: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			n, err := parse(tt.expression)
+			require.NoError(t, err)
+			v, err := n.eval(m)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, v)
+		})
+	}
+}
+
+func TestEvalError(t *testing.T) {
+	m := testutil.MustMetric(
+		"mem",
+		map[string]string{"host": "server01"},
+		map[string]interface{}{
+			"used":  int64(25),
+			"state": "ok",
+		},
+		time.Unix(0, 0),
+	)
+
+	var tests = []struct {
+		expression string
+		missing    bool
+	}{
+		{expression: "missing + 1", missing: true},
+		{expression: "used > 10 && missing", missing: true},
+		{expression: "used + state"},
+		{expression: "used > state"},
+		{expression: "-state"},
+		{expression: "!used"},
+		{expression: "used ? 1 : 2"},
+		{expression: "used % 0"},
+		{expression: "tag('missing')", missing: true},
+		{expression: "abs(field('missing'))", missing: true},
+		{expression: "int('abc')"},
+		{expression: "sqrt(state)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			n, err := parse(tt.expression)
+			require.NoError(t, err)
+			_, err = n.eval(m)
+			require.Error(t, err)
+			_, missing := err.(*missingError)
+			require.Equal(t, tt.missing, missing)
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	var tests = []string{
+		"",
+		"used +",
+		"(used + 1",
+		"used + 1)",
+		"used $ 1",
+		"'unterminated",
+		"unknown(1)",
+		"pow(1)",
+		"min()",
+		"if(true, 1)",
+		"used ? 1",
+		"1.2.3",
+	}
+	for _, expression := range tests {
+		t.Run(expression, func(t *testing.T) {
+			_, err := parse(expression)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package expression
+
+import (
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+const sampleConfig = `
+  ## Fields to add or replace, evaluated in order so that later expressions
+  ## can use the result of earlier ones.
+  [[processors.expression.field]]
+    ## Name of the field to set
+    name = "used_percent"
+
+    ## Expression computing the value
+    expression = "used / total * 100"
+`
+
+type Expression struct {
+	Fields []*Field `toml:"field"`
+}
+
+// Field sets a field to the result of an expression.
+type Field struct {
+	Name       string `toml:"name"`
+	Expression string `toml:"expression"`
+
+	root node
+}
+
+func (e *Expression) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *Expression) Description() string {
+	return "Set fields to the result of expressions using the fields and tags of each metric."
+}
+
+func (e *Expression) Init() error {
+	for _, f := range e.Fields {
+		if f.Name == "" {
+			return fmt.Errorf("field name is required")
+		}
+
+		var err error
+		f.root, err = parse(f.Expression)
+		if err != nil {
+			return fmt.Errorf("invalid expression for field %q: %v", f.Name, err)
+		}
+	}
+	return nil
+}
+
+func (e *Expression) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range in {
+		for _, f := range e.Fields {
+			// When the expression cannot be evaluated the field is left
+			// unset, or unchanged if the metric already has it.  Missing
+			// fields and tags are common, for example when an input only
+			// reports some fields, so they are only logged in debug mode.
+			value, err := evaluate(f.root, m)
+			if _, ok := err.(*missingError); ok {
+				log.Printf("D! [processors.expression] could not evaluate field %q of metric %q: %v",
+					f.Name, m.Name(), err)
+				continue
+			} else if err != nil {
+				log.Printf("E! [processors.expression] could not evaluate field %q of metric %q: %v",
+					f.Name, m.Name(), err)
+				continue
+			}
+			m.AddField(f.Name, value)
+		}
+	}
+	return in
+}
+
+// evaluate returns the value of the expression for the metric, the result
+// must be a valid field value.
+func evaluate(root node, m telegraf.Metric) (interface{}, error) {
+	value, err := root.eval(m)
+	if err != nil {
+		return nil, err
+	}
+
+	if v, ok := value.(float64); ok && (math.IsNaN(v) || math.IsInf(v, 0)) {
+		return nil, fmt.Errorf("result is %v", v)
+	}
+	return value, nil
+}
+
+func init() {
+	processors.Add("expression", func() telegraf.Processor {
+		return &Expression{}
+	})
+}
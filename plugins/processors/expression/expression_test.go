@@ -0,0 +1,111 @@
+package expression
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply(t *testing.T) {
+	plugin := &Expression{
+		Fields: []*Field{
+			{Name: "used_percent", Expression: "used / total * 100"},
+			{Name: "high", Expression: "used_percent > 50"},
+			{Name: "error_ratio", Expression: "errors / (requests + 1)"},
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric(
+			"mem",
+			map[string]string{},
+			map[string]interface{}{"used": int64(75), "total": int64(100)},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"http",
+			map[string]string{},
+			map[string]interface{}{"errors": int64(1), "requests": int64(3)},
+			time.Unix(0, 0),
+		),
+	}
+
+	// Expressions referencing missing fields are skipped for the metric.
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"mem",
+			map[string]string{},
+			map[string]interface{}{
+				"used":         int64(75),
+				"total":        int64(100),
+				"used_percent": 75.0,
+				"high":         true,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"http",
+			map[string]string{},
+			map[string]interface{}{
+				"errors":      int64(1),
+				"requests":    int64(3),
+				"error_ratio": 0.25,
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestReplaceField(t *testing.T) {
+	plugin := &Expression{
+		Fields: []*Field{
+			{Name: "value", Expression: "value * 2"},
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	m := testutil.MustMetric("test", map[string]string{},
+		map[string]interface{}{"value": int64(21)}, time.Unix(0, 0))
+	plugin.Apply(m)
+
+	value, _ := m.GetField("value")
+	require.Equal(t, int64(42), value)
+}
+
+func TestInvalidResult(t *testing.T) {
+	plugin := &Expression{
+		Fields: []*Field{
+			{Name: "ratio", Expression: "errors / requests"},
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	m := testutil.MustMetric("test", map[string]string{},
+		map[string]interface{}{"errors": int64(1), "requests": int64(0)}, time.Unix(0, 0))
+	plugin.Apply(m)
+
+	require.False(t, m.HasField("ratio"))
+}
+
+func TestInit(t *testing.T) {
+	plugin := &Expression{
+		Fields: []*Field{
+			{Name: "value", Expression: "value *"},
+		},
+	}
+	require.Error(t, plugin.Init())
+
+	plugin = &Expression{
+		Fields: []*Field{
+			{Expression: "value"},
+		},
+	}
+	require.Error(t, plugin.Init())
+}
@@ -0,0 +1,204 @@
+package expression
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/influxdata/telegraf"
+)
+
+// function is a function that can be called from an expression, args is the
+// number of arguments or -1 if it accepts one or more.
+type function struct {
+	args int
+	call func(m telegraf.Metric, args []interface{}) (interface{}, error)
+}
+
+var functions = map[string]function{
+	"abs":   math1(math.Abs),
+	"ceil":  math1(math.Ceil),
+	"floor": math1(math.Floor),
+	"round": math1(round),
+	"sqrt":  math1(math.Sqrt),
+	"exp":   math1(math.Exp),
+	"log":   math1(math.Log),
+	"log10": math1(math.Log10),
+	"pow":   {args: 2, call: pow},
+	"min":   {args: -1, call: minmax(func(a, b float64) bool { return a < b })},
+	"max":   {args: -1, call: minmax(func(a, b float64) bool { return a > b })},
+
+	"int":    {args: 1, call: toInt},
+	"float":  {args: 1, call: toFloat},
+	"string": {args: 1, call: toString},
+	"bool":   {args: 1, call: toBool},
+
+	"name":   {args: 0, call: name},
+	"tag":    {args: 1, call: tag},
+	"field":  {args: 1, call: field},
+	"exists": {args: 1, call: exists},
+}
+
+// math1 wraps a float function of one argument.
+func math1(fn func(float64) float64) function {
+	return function{
+		args: 1,
+		call: func(m telegraf.Metric, args []interface{}) (interface{}, error) {
+			x, ok := toNumber(args[0])
+			if !ok {
+				return nil, fmt.Errorf("expected number, got %s", typeName(args[0]))
+			}
+			return fn(x), nil
+		},
+	}
+}
+
+// round rounds half away from zero.
+func round(x float64) float64 {
+	if x < 0 {
+		return math.Ceil(x - 0.5)
+	}
+	return math.Floor(x + 0.5)
+}
+
+func pow(m telegraf.Metric, args []interface{}) (interface{}, error) {
+	x, xok := toNumber(args[0])
+	y, yok := toNumber(args[1])
+	if !xok || !yok {
+		return nil, fmt.Errorf("expected numbers, got %s and %s", typeName(args[0]), typeName(args[1]))
+	}
+	return math.Pow(x, y), nil
+}
+
+// minmax returns a function selecting the argument for which less returns
+// true when compared to all others.  The argument is returned unchanged so
+// integers stay integers.
+func minmax(less func(a, b float64) bool) func(m telegraf.Metric, args []interface{}) (interface{}, error) {
+	return func(m telegraf.Metric, args []interface{}) (interface{}, error) {
+		var result interface{}
+		var best float64
+		for i, arg := range args {
+			x, ok := toNumber(arg)
+			if !ok {
+				return nil, fmt.Errorf("expected number, got %s", typeName(arg))
+			}
+			if i == 0 || less(x, best) {
+				result, best = arg, x
+			}
+		}
+		return result, nil
+	}
+}
+
+func toInt(m telegraf.Metric, args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case int64:
+		return v, nil
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("cannot convert %v to integer", v)
+		}
+		return int64(v), nil
+	case bool:
+		if v {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case string:
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return i, nil
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert %q to integer", v)
+		}
+		return int64(f), nil
+	}
+	return nil, fmt.Errorf("cannot convert %s to integer", typeName(args[0]))
+}
+
+func toFloat(m telegraf.Metric, args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case bool:
+		if v {
+			return 1.0, nil
+		}
+		return 0.0, nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert %q to float", v)
+		}
+		return f, nil
+	}
+	return nil, fmt.Errorf("cannot convert %s to float", typeName(args[0]))
+}
+
+func toString(m telegraf.Metric, args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case string:
+		return v, nil
+	}
+	return nil, fmt.Errorf("cannot convert %s to string", typeName(args[0]))
+}
+
+func toBool(m telegraf.Metric, args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case int64:
+		return v != 0, nil
+	case float64:
+		return v != 0, nil
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert %q to boolean", v)
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("cannot convert %s to boolean", typeName(args[0]))
+}
+
+func name(m telegraf.Metric, args []interface{}) (interface{}, error) {
+	return m.Name(), nil
+}
+
+func tag(m telegraf.Metric, args []interface{}) (interface{}, error) {
+	key, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("expected string, got %s", typeName(args[0]))
+	}
+	value, ok := m.GetTag(key)
+	if !ok {
+		return nil, &missingError{kind: "tag", key: key}
+	}
+	return value, nil
+}
+
+func field(m telegraf.Metric, args []interface{}) (interface{}, error) {
+	key, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("expected string, got %s", typeName(args[0]))
+	}
+	return getField(m, key)
+}
+
+// exists returns true if the metric has a field with the name.
+func exists(m telegraf.Metric, args []interface{}) (interface{}, error) {
+	key, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("expected string, got %s", typeName(args[0]))
+	}
+	return m.HasField(key), nil
+}
@@ -0,0 +1,315 @@
+package geoip
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+const sampleConfig = `
+  ## MaxMind DB files to search, such as the GeoLite2 City and ASN databases.
+  ## When a value is found in more than one database the first is used.
+  databases = ["/var/lib/GeoIP/GeoLite2-City.mmdb"]
+
+  ## Number of lookup results to keep in memory.
+  # cache_size = 1000
+
+  ## Interval to check the database files for changes, the files are loaded
+  ## again when they are modified.  Set to 0 to disable.
+  # reload_interval = "1m"
+
+  [[processors.geoip.lookup]]
+    ## Name of the tag containing the IP address
+    tag = "client_ip"
+
+    ## Name of the field containing the IP address
+    # field = "client_ip"
+
+    ## Tags to add, the key is the tag name and the value is the path of the
+    ## value in the database record.
+    [processors.geoip.lookup.tags]
+      country = "country.iso_code"
+      city = "city.names.en"
+
+    ## Fields to add, the key is the field name and the value is the path of
+    ## the value in the database record.
+    [processors.geoip.lookup.fields]
+      latitude = "location.latitude"
+      longitude = "location.longitude"
+`
+
+type GeoIP struct {
+	Databases      []string          `toml:"databases"`
+	CacheSize      int               `toml:"cache_size"`
+	ReloadInterval internal.Duration `toml:"reload_interval"`
+	Lookups        []Lookup          `toml:"lookup"`
+
+	databases []*database
+	cache     *lru
+	lastCheck time.Time
+}
+
+// Lookup describes where to find an IP address in a metric and the values to
+// add to the metric.
+type Lookup struct {
+	Tag    string            `toml:"tag"`
+	Field  string            `toml:"field"`
+	Tags   map[string]string `toml:"tags"`
+	Fields map[string]string `toml:"fields"`
+}
+
+// database is a loaded database file along with the file information used to
+// detect changes.
+type database struct {
+	path    string
+	modTime time.Time
+	size    int64
+	reader  *maxminddb.Reader
+}
+
+func New() *GeoIP {
+	return &GeoIP{
+		CacheSize:      1000,
+		ReloadInterval: internal.Duration{Duration: time.Minute},
+	}
+}
+
+func (g *GeoIP) SampleConfig() string {
+	return sampleConfig
+}
+
+func (g *GeoIP) Description() string {
+	return "Add geographic information to metrics by looking up IP addresses in MaxMind databases."
+}
+
+func (g *GeoIP) Init() error {
+	if len(g.Databases) == 0 {
+		return fmt.Errorf("no databases configured")
+	}
+
+	for _, lookup := range g.Lookups {
+		if (lookup.Tag == "") == (lookup.Field == "") {
+			return fmt.Errorf("lookup must have exactly one of tag or field")
+		}
+		if len(lookup.Tags) == 0 && len(lookup.Fields) == 0 {
+			return fmt.Errorf("lookup must have tags or fields to add")
+		}
+	}
+
+	g.databases = make([]*database, 0, len(g.Databases))
+	for _, path := range g.Databases {
+		d, err := openDatabase(path)
+		if err != nil {
+			return err
+		}
+		g.databases = append(g.databases, d)
+	}
+
+	g.cache = newLRU(g.CacheSize)
+	g.lastCheck = time.Now()
+	return nil
+}
+
+// openDatabase reads the database file into memory, rather than mapping it,
+// so that the file can be replaced while it is in use.
+func openDatabase(path string) (*database, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := maxminddb.FromBytes(buf)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return &database{
+		path:    path,
+		modTime: info.ModTime(),
+		size:    info.Size(),
+		reader:  reader,
+	}, nil
+}
+
+func (g *GeoIP) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	g.reload()
+
+	for _, m := range in {
+		for _, lookup := range g.Lookups {
+			g.apply(m, lookup)
+		}
+	}
+	return in
+}
+
+func (g *GeoIP) apply(m telegraf.Metric, lookup Lookup) {
+	var addr string
+	if lookup.Tag != "" {
+		addr, _ = m.GetTag(lookup.Tag)
+	} else if v, ok := m.GetField(lookup.Field); ok {
+		addr, _ = v.(string)
+	}
+	if addr == "" {
+		return
+	}
+
+	records := g.lookup(addr)
+	if records == nil {
+		return
+	}
+
+	for key, path := range lookup.Tags {
+		if value, ok := find(records, path); ok {
+			m.AddTag(key, formatTag(value))
+		}
+	}
+
+	for key, path := range lookup.Fields {
+		if value, ok := find(records, path); ok {
+			m.AddField(key, value)
+		}
+	}
+}
+
+// lookup returns the record of each database for the address, the result is
+// nil if the address is invalid.
+func (g *GeoIP) lookup(addr string) []interface{} {
+	if records, ok := g.cache.get(addr); ok {
+		return records
+	}
+
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		log.Printf("D! [processors.geoip] invalid ip address %q", addr)
+		return nil
+	}
+
+	records := make([]interface{}, 0, len(g.databases))
+	for _, d := range g.databases {
+		var record interface{}
+		err := d.reader.Lookup(ip, &record)
+		if err != nil {
+			log.Printf("D! [processors.geoip] could not look up %s in %s: %v", addr, d.path, err)
+		}
+		records = append(records, record)
+	}
+
+	g.cache.add(addr, records)
+	return records
+}
+
+// reload loads the database files again if they were modified since they
+// were last loaded.
+func (g *GeoIP) reload() {
+	if g.ReloadInterval.Duration <= 0 {
+		return
+	}
+
+	now := time.Now()
+	if now.Sub(g.lastCheck) < g.ReloadInterval.Duration {
+		return
+	}
+	g.lastCheck = now
+
+	for i, d := range g.databases {
+		info, err := os.Stat(d.path)
+		if err != nil {
+			log.Printf("E! [processors.geoip] could not check database: %v", err)
+			continue
+		}
+		if info.ModTime().Equal(d.modTime) && info.Size() == d.size {
+			continue
+		}
+
+		// When the file cannot be loaded, for example because it is still
+		// being written, the previous database is kept and the load is
+		// attempted again on the next check.
+		reloaded, err := openDatabase(d.path)
+		if err != nil {
+			log.Printf("E! [processors.geoip] could not reload database: %v", err)
+			continue
+		}
+		log.Printf("I! [processors.geoip] reloaded database %s", d.path)
+		g.databases[i] = reloaded
+		g.cache.purge()
+	}
+}
+
+// find returns the value at the path in the first record containing it.  The
+// path is a dot separated list of map keys and array indexes, only scalar
+// values are returned.  Integers are returned as int64 or uint64, floating
+// point numbers as float64 and 128 bit integers as a decimal string.
+func find(records []interface{}, path string) (interface{}, bool) {
+	keys := strings.Split(path, ".")
+	for _, record := range records {
+		if value, ok := findValue(record, keys); ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+func findValue(value interface{}, keys []string) (interface{}, bool) {
+	for _, key := range keys {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if value, ok = v[key]; !ok {
+				return nil, false
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+
+	switch v := value.(type) {
+	case string, float64, int64, uint64, bool:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return int64(v), true
+	case *big.Int:
+		return v.String(), true
+	}
+	return nil, false
+}
+
+func formatTag(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func init() {
+	processors.Add("geoip", func() telegraf.Processor {
+		return New()
+	})
+}
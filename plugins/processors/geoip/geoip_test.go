@@ -0,0 +1,254 @@
+package geoip
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+var cityRecord = map[string]interface{}{
+	"city": map[string]interface{}{
+		"names": map[string]interface{}{"en": "Berlin"},
+	},
+	"country": map[string]interface{}{
+		"iso_code": "DE",
+	},
+	"location": map[string]interface{}{
+		"latitude":  52.5196,
+		"longitude": 13.4069,
+	},
+}
+
+var asnRecord = map[string]interface{}{
+	"autonomous_system_number":       uint32(64496),
+	"autonomous_system_organization": "Example",
+}
+
+func writeDatabase(t *testing.T, dir, name string, networks []network) string {
+	path := filepath.Join(dir, name)
+	err := ioutil.WriteFile(path, buildDatabase(t, 6, networks), 0644)
+	require.NoError(t, err)
+	return path
+}
+
+func TestApply(t *testing.T) {
+	dir, err := ioutil.TempDir("", "geoip")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	plugin := New()
+	plugin.Databases = []string{
+		writeDatabase(t, dir, "city.mmdb", []network{
+			{cidr: "192.0.2.0/24", record: cityRecord},
+		}),
+		writeDatabase(t, dir, "asn.mmdb", []network{
+			{cidr: "192.0.2.0/24", record: asnRecord},
+		}),
+	}
+	plugin.Lookups = []Lookup{
+		{
+			Tag: "client_ip",
+			Tags: map[string]string{
+				"country": "country.iso_code",
+				"city":    "city.names.en",
+				"asn":     "autonomous_system_number",
+			},
+			Fields: map[string]string{
+				"latitude":  "location.latitude",
+				"longitude": "location.longitude",
+				"missing":   "location.missing",
+			},
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric(
+			"nginx",
+			map[string]string{"client_ip": "192.0.2.1"},
+			map[string]interface{}{"request": 1},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"nginx",
+			map[string]string{"client_ip": "198.51.100.1"},
+			map[string]interface{}{"request": 1},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"nginx",
+			map[string]string{"client_ip": "not an address"},
+			map[string]interface{}{"request": 1},
+			time.Unix(0, 0),
+		),
+	}
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"nginx",
+			map[string]string{
+				"client_ip": "192.0.2.1",
+				"country":   "DE",
+				"city":      "Berlin",
+				"asn":       "64496",
+			},
+			map[string]interface{}{
+				"request":   1,
+				"latitude":  52.5196,
+				"longitude": 13.4069,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"nginx",
+			map[string]string{"client_ip": "198.51.100.1"},
+			map[string]interface{}{"request": 1},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"nginx",
+			map[string]string{"client_ip": "not an address"},
+			map[string]interface{}{"request": 1},
+			time.Unix(0, 0),
+		),
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+	require.Equal(t, 2, plugin.cache.len())
+}
+
+func TestApplyField(t *testing.T) {
+	dir, err := ioutil.TempDir("", "geoip")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	plugin := New()
+	plugin.Databases = []string{
+		writeDatabase(t, dir, "city.mmdb", []network{
+			{cidr: "2001:db8::/32", record: cityRecord},
+		}),
+	}
+	plugin.Lookups = []Lookup{
+		{
+			Field: "src",
+			Tags:  map[string]string{"src_country": "country.iso_code"},
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	m := testutil.MustMetric(
+		"syslog",
+		map[string]string{},
+		map[string]interface{}{"src": "2001:db8::1"},
+		time.Unix(0, 0),
+	)
+	plugin.Apply(m)
+
+	value, ok := m.GetTag("src_country")
+	require.True(t, ok)
+	require.Equal(t, "DE", value)
+}
+
+func TestReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "geoip")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := writeDatabase(t, dir, "city.mmdb", []network{
+		{cidr: "192.0.2.0/24", record: map[string]interface{}{"name": "old"}},
+	})
+
+	plugin := New()
+	plugin.Databases = []string{path}
+	plugin.Lookups = []Lookup{
+		{
+			Tag:  "client_ip",
+			Tags: map[string]string{"location": "name"},
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	m := testutil.MustMetric("nginx", map[string]string{"client_ip": "192.0.2.1"},
+		map[string]interface{}{"request": 1}, time.Unix(0, 0))
+	plugin.Apply(m)
+	value, _ := m.GetTag("location")
+	require.Equal(t, "old", value)
+
+	writeDatabase(t, dir, "city.mmdb", []network{
+		{cidr: "192.0.2.0/24", record: map[string]interface{}{"name": "updated"}},
+	})
+	plugin.lastCheck = time.Now().Add(-time.Hour)
+
+	m = testutil.MustMetric("nginx", map[string]string{"client_ip": "192.0.2.1"},
+		map[string]interface{}{"request": 1}, time.Unix(0, 0))
+	plugin.Apply(m)
+	value, _ = m.GetTag("location")
+	require.Equal(t, "updated", value)
+}
+
+func TestInit(t *testing.T) {
+	var tests = []struct {
+		name      string
+		databases []string
+		lookups   []Lookup
+	}{
+		{
+			name: "no databases",
+		},
+		{
+			name:      "missing database",
+			databases: []string{"/nonexistent.mmdb"},
+		},
+		{
+			name:      "tag and field",
+			databases: []string{"/nonexistent.mmdb"},
+			lookups: []Lookup{
+				{
+					Tag:   "ip",
+					Field: "ip",
+					Tags:  map[string]string{"country": "country.iso_code"},
+				},
+			},
+		},
+		{
+			name:      "nothing to add",
+			databases: []string{"/nonexistent.mmdb"},
+			lookups:   []Lookup{{Tag: "ip"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := New()
+			plugin.Databases = tt.databases
+			plugin.Lookups = tt.lookups
+			require.Error(t, plugin.Init())
+		})
+	}
+}
+
+func TestLRU(t *testing.T) {
+	c := newLRU(2)
+	c.add("a", []interface{}{"a"})
+	c.add("b", []interface{}{"b"})
+
+	_, ok := c.get("a")
+	require.True(t, ok)
+
+	c.add("c", []interface{}{"c"})
+	require.Equal(t, 2, c.len())
+
+	_, ok = c.get("b")
+	require.False(t, ok)
+	_, ok = c.get("a")
+	require.True(t, ok)
+
+	c.purge()
+	require.Equal(t, 0, c.len())
+}
@@ -0,0 +1,63 @@
+package geoip
+
+import (
+	"container/list"
+)
+
+// lru is a fixed size cache of lookup results that evicts the least recently
+// used entry.
+type lru struct {
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type lruEntry struct {
+	key   string
+	value []interface{}
+}
+
+func newLRU(size int) *lru {
+	return &lru{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (c *lru) get(key string) ([]interface{}, bool) {
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry).value, true
+}
+
+func (c *lru) add(key string, value []interface{}) {
+	if c.size <= 0 {
+		return
+	}
+
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		e.Value.(*lruEntry).value = value
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (c *lru) len() int {
+	return c.order.Len()
+}
+
+func (c *lru) purge() {
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+}
@@ -0,0 +1,271 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"net"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/oschwald/maxminddb-golang"
+	"github.com/stretchr/testify/require"
+)
+
+// The MaxMind DB file format is described at
+// https://maxmind.github.io/MaxMind-DB/
+
+// metadataStart marks the beginning of the metadata section at the end of a
+// MaxMind DB file.
+var metadataStart = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// dataSectionSeparator is the number of zero bytes between the search tree
+// and the data section.
+const dataSectionSeparator = 16
+
+// Data types of the data section.
+const (
+	typeString = 2
+	typeDouble = 3
+	typeUint16 = 5
+	typeUint32 = 6
+	typeMap    = 7
+	typeInt32  = 8
+	typeArray  = 11
+	typeBool   = 14
+)
+
+// network is a network and its record used to build a test database.
+type network struct {
+	cidr   string
+	record interface{}
+}
+
+type treeNode struct {
+	children [2]*treeNode
+	data     [2]int
+}
+
+// buildDatabase creates a MaxMind DB file with 24 bit records containing the
+// networks.
+func buildDatabase(t *testing.T, ipVersion int, networks []network) []byte {
+	root := &treeNode{data: [2]int{-1, -1}}
+
+	var data bytes.Buffer
+	for _, n := range networks {
+		_, ipnet, err := net.ParseCIDR(n.cidr)
+		require.NoError(t, err)
+
+		ip := ipnet.IP.To16()
+		ones, _ := ipnet.Mask.Size()
+		if ipVersion == 4 {
+			ip = ipnet.IP.To4()
+		} else if ip4 := ipnet.IP.To4(); ip4 != nil {
+			// IPv4 networks are stored in IPv6 databases as ::/96.
+			ip = append(make(net.IP, 12), ip4...)
+			ones += 96
+		}
+
+		offset := data.Len()
+		data.Write(encode(n.record))
+
+		node := root
+		for i := 0; i < ones; i++ {
+			bit := ip[i>>3] >> (7 - uint(i&7)) & 1
+			if i == ones-1 {
+				node.data[bit] = offset
+				break
+			}
+			if node.children[bit] == nil {
+				node.children[bit] = &treeNode{data: [2]int{-1, -1}}
+			}
+			node = node.children[bit]
+		}
+	}
+
+	// Number the nodes in depth first order.
+	var nodes []*treeNode
+	index := make(map[*treeNode]int)
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		index[n] = len(nodes)
+		nodes = append(nodes, n)
+		for _, child := range n.children {
+			if child != nil {
+				walk(child)
+			}
+		}
+	}
+	walk(root)
+
+	var buf bytes.Buffer
+	for _, n := range nodes {
+		for bit := 0; bit < 2; bit++ {
+			record := len(nodes)
+			if n.children[bit] != nil {
+				record = index[n.children[bit]]
+			} else if n.data[bit] >= 0 {
+				record = len(nodes) + dataSectionSeparator + n.data[bit]
+			}
+			buf.Write([]byte{byte(record >> 16), byte(record >> 8), byte(record)})
+		}
+	}
+	buf.Write(make([]byte, dataSectionSeparator))
+	buf.Write(data.Bytes())
+	buf.Write(metadataStart)
+	buf.Write(encode(map[string]interface{}{
+		"node_count":                  uint32(len(nodes)),
+		"record_size":                 uint16(24),
+		"ip_version":                  uint16(ipVersion),
+		"database_type":               "Test",
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+	}))
+	return buf.Bytes()
+}
+
+// encode encodes a value in the data section format.
+func encode(v interface{}) []byte {
+	var payload []byte
+	var typ, size int
+	switch v := v.(type) {
+	case string:
+		typ, payload = typeString, []byte(v)
+	case float64:
+		typ, payload = typeDouble, make([]byte, 8)
+		binary.BigEndian.PutUint64(payload, math.Float64bits(v))
+	case uint16:
+		typ, payload = typeUint16, []byte{byte(v >> 8), byte(v)}
+	case uint32:
+		typ, payload = typeUint32, make([]byte, 4)
+		binary.BigEndian.PutUint32(payload, v)
+	case int32:
+		typ, payload = typeInt32, make([]byte, 4)
+		binary.BigEndian.PutUint32(payload, uint32(v))
+	case bool:
+		typ = typeBool
+		if v {
+			size = 1
+		}
+	case []interface{}:
+		typ, size = typeArray, len(v)
+		for _, e := range v {
+			payload = append(payload, encode(e)...)
+		}
+	case map[string]interface{}:
+		typ, size = typeMap, len(v)
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			payload = append(payload, encode(k)...)
+			payload = append(payload, encode(v[k])...)
+		}
+	default:
+		panic("unsupported type")
+	}
+
+	if typ != typeArray && typ != typeMap && typ != typeBool {
+		size = len(payload)
+	}
+
+	var out []byte
+	if size < 29 {
+		out = append(out, byte(size))
+	} else {
+		out = append(out, 29, byte(size-29))
+	}
+	if typ > 7 {
+		out = append([]byte{out[0], byte(typ - 7)}, out[1:]...)
+	} else {
+		out[0] |= byte(typ << 5)
+	}
+	return append(out, payload...)
+}
+
+func TestDatabaseLookup(t *testing.T) {
+	city := map[string]interface{}{
+		"city": map[string]interface{}{
+			"names": map[string]interface{}{"en": "Berlin"},
+		},
+		"location": map[string]interface{}{
+			"latitude":  52.5196,
+			"longitude": 13.4069,
+		},
+		"subdivisions": []interface{}{
+			map[string]interface{}{"iso_code": "BE"},
+		},
+	}
+
+	for _, ipVersion := range []int{4, 6} {
+		buf := buildDatabase(t, ipVersion, []network{
+			{cidr: "192.0.2.0/24", record: city},
+			{cidr: "198.51.100.0/25", record: "short"},
+		})
+
+		reader, err := maxminddb.FromBytes(buf)
+		require.NoError(t, err)
+		require.Equal(t, uint(ipVersion), reader.Metadata.IPVersion)
+		require.Equal(t, "Test", reader.Metadata.DatabaseType)
+
+		var record interface{}
+		require.NoError(t, reader.Lookup(net.ParseIP("192.0.2.42"), &record))
+		require.Equal(t, city, record)
+
+		record = nil
+		require.NoError(t, reader.Lookup(net.ParseIP("198.51.100.1"), &record))
+		require.Equal(t, "short", record)
+
+		record = nil
+		require.NoError(t, reader.Lookup(net.ParseIP("198.51.100.200"), &record))
+		require.Nil(t, record)
+	}
+}
+
+func TestFindValueTypes(t *testing.T) {
+	record := map[string]interface{}{
+		"float":   float32(1.5),
+		"int":     int(-1),
+		"uint":    uint64(500),
+		"uint128": new(big.Int).Lsh(big.NewInt(1), 64),
+		"bytes":   []byte("a"),
+	}
+
+	var tests = []struct {
+		path     string
+		expected interface{}
+	}{
+		{path: "float", expected: 1.5},
+		{path: "int", expected: int64(-1)},
+		{path: "uint", expected: uint64(500)},
+		{path: "uint128", expected: "18446744073709551616"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			value, ok := find([]interface{}{record}, tt.path)
+			require.True(t, ok)
+			require.Equal(t, tt.expected, value)
+		})
+	}
+
+	_, ok := find([]interface{}{record}, "bytes")
+	require.False(t, ok)
+}
+
+func TestOpenDatabaseInvalid(t *testing.T) {
+	f, err := ioutil.TempFile("", "geoip")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString("not a database")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	_, err = openDatabase(f.Name())
+	require.Error(t, err)
+}
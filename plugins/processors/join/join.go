@@ -0,0 +1,246 @@
+package join
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+const sampleConfig = `
+  ## Measurement receiving the joined tags and fields.
+  measurement = "procstat"
+
+  ## Measurement providing the tags and fields.
+  source_measurement = "docker_container_mem"
+
+  ## Tags that must have equal values for metrics to be joined, metrics
+  ## missing one of the tags are passed through unchanged.
+  join_tags = ["container_name"]
+
+  ## Tags and fields copied from the source metric, replacing existing tags
+  ## and fields.  Globs are supported.
+  tags = ["label_*"]
+  # fields = []
+
+  ## Time metrics are held waiting for a source metric, after which they are
+  ## passed through unchanged.  This is also the time a source metric is
+  ## used for joining.  Held metrics are only passed on when the processor
+  ## receives another metric after the window, or when Telegraf stops.
+  # window = "10s"
+
+  ## If true, source metrics are dropped instead of being passed through.
+  # drop_source = false
+`
+
+type Join struct {
+	Measurement       string            `toml:"measurement"`
+	SourceMeasurement string            `toml:"source_measurement"`
+	JoinTags          []string          `toml:"join_tags"`
+	Tags              []string          `toml:"tags"`
+	Fields            []string          `toml:"fields"`
+	Window            internal.Duration `toml:"window"`
+	DropSource        bool              `toml:"drop_source"`
+
+	tagFilter   filter.Filter
+	fieldFilter filter.Filter
+	sources     map[string]*source
+	pending     []*pending
+	lastExpire  time.Time
+	now         func() time.Time
+}
+
+// source is the last source metric of a key.
+type source struct {
+	metric telegraf.Metric
+	added  time.Time
+}
+
+// pending is a metric held until a source metric with the same key arrives.
+type pending struct {
+	metric telegraf.Metric
+	key    string
+	added  time.Time
+}
+
+func New() *Join {
+	return &Join{
+		Window: internal.Duration{Duration: 10 * time.Second},
+		now:    time.Now,
+	}
+}
+
+func (j *Join) SampleConfig() string {
+	return sampleConfig
+}
+
+func (j *Join) Description() string {
+	return "Join tags and fields of metrics of one measurement into metrics of another."
+}
+
+func (j *Join) Init() error {
+	if j.Measurement == "" || j.SourceMeasurement == "" {
+		return fmt.Errorf("measurement and source_measurement are required")
+	}
+	if j.Measurement == j.SourceMeasurement {
+		return fmt.Errorf("measurement and source_measurement must differ")
+	}
+	if len(j.JoinTags) == 0 {
+		return fmt.Errorf("join_tags is required")
+	}
+	if len(j.Tags) == 0 && len(j.Fields) == 0 {
+		return fmt.Errorf("tags or fields to copy are required")
+	}
+
+	var err error
+	j.tagFilter, err = filter.Compile(j.Tags)
+	if err != nil {
+		return fmt.Errorf("invalid tags: %v", err)
+	}
+	j.fieldFilter, err = filter.Compile(j.Fields)
+	if err != nil {
+		return fmt.Errorf("invalid fields: %v", err)
+	}
+
+	j.sources = make(map[string]*source)
+	j.lastExpire = j.now()
+	return nil
+}
+
+func (j *Join) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := j.now()
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		switch m.Name() {
+		case j.SourceMeasurement:
+			if key, ok := j.key(m); ok {
+				s := &source{metric: metric.FromMetric(m), added: now}
+				j.sources[key] = s
+				out = append(out, j.release(key, s)...)
+			}
+
+			if j.DropSource {
+				m.Drop()
+				continue
+			}
+		case j.Measurement:
+			key, ok := j.key(m)
+			if !ok {
+				break
+			}
+
+			if s, ok := j.sources[key]; ok && now.Sub(s.added) < j.Window.Duration {
+				j.join(m, s.metric)
+				break
+			}
+
+			// The metric is held as is, so that it is only delivered once it
+			// is passed on.
+			j.pending = append(j.pending, &pending{
+				metric: m,
+				key:    key,
+				added:  now,
+			})
+			continue
+		}
+		out = append(out, m)
+	}
+
+	return append(out, j.expire(now)...)
+}
+
+// Stop returns the held metrics unchanged so that they are not lost.
+func (j *Join) Stop() []telegraf.Metric {
+	held := make([]telegraf.Metric, 0, len(j.pending))
+	for _, p := range j.pending {
+		held = append(held, p.metric)
+	}
+	j.pending = nil
+	return held
+}
+
+// release joins and returns the pending metrics with the key.
+func (j *Join) release(key string, s *source) []telegraf.Metric {
+	var released []telegraf.Metric
+	remaining := j.pending[:0]
+	for _, p := range j.pending {
+		if p.key == key {
+			j.join(p.metric, s.metric)
+			released = append(released, p.metric)
+			continue
+		}
+		remaining = append(remaining, p)
+	}
+	j.pending = remaining
+	return released
+}
+
+// expire removes source metrics older than the window and returns the
+// pending metrics held for longer than the window.  It runs at most ten times
+// per window so that the metrics are not checked on every call.
+func (j *Join) expire(now time.Time) []telegraf.Metric {
+	if now.Sub(j.lastExpire) < j.Window.Duration/10 {
+		return nil
+	}
+	j.lastExpire = now
+
+	for key, s := range j.sources {
+		if now.Sub(s.added) >= j.Window.Duration {
+			delete(j.sources, key)
+		}
+	}
+
+	var expired []telegraf.Metric
+	remaining := j.pending[:0]
+	for _, p := range j.pending {
+		if now.Sub(p.added) >= j.Window.Duration {
+			expired = append(expired, p.metric)
+			continue
+		}
+		remaining = append(remaining, p)
+	}
+	j.pending = remaining
+	return expired
+}
+
+// join copies the selected tags and fields of the source metric into m.
+func (j *Join) join(m, src telegraf.Metric) {
+	if j.tagFilter != nil {
+		for _, tag := range src.TagList() {
+			if j.tagFilter.Match(tag.Key) {
+				m.AddTag(tag.Key, tag.Value)
+			}
+		}
+	}
+	if j.fieldFilter != nil {
+		for _, field := range src.FieldList() {
+			if j.fieldFilter.Match(field.Key) {
+				m.AddField(field.Key, field.Value)
+			}
+		}
+	}
+}
+
+// key returns the values of the join tags, or false if a tag is missing.
+func (j *Join) key(m telegraf.Metric) (string, bool) {
+	values := make([]string, 0, len(j.JoinTags))
+	for _, k := range j.JoinTags {
+		v, ok := m.GetTag(k)
+		if !ok {
+			return "", false
+		}
+		values = append(values, v)
+	}
+	return strings.Join(values, "\x00"), true
+}
+
+func init() {
+	processors.Add("join", func() telegraf.Processor {
+		return New()
+	})
+}
@@ -0,0 +1,267 @@
+package join
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Unix(1000, 0)
+
+func newJoin() *Join {
+	plugin := New()
+	plugin.Measurement = "procstat"
+	plugin.SourceMeasurement = "docker"
+	plugin.JoinTags = []string{"container"}
+	plugin.Tags = []string{"label_*"}
+	plugin.Fields = []string{"limit"}
+	plugin.now = func() time.Time { return now }
+	return plugin
+}
+
+func procstat(container string) telegraf.Metric {
+	return testutil.MustMetric(
+		"procstat",
+		map[string]string{"container": container},
+		map[string]interface{}{"memory_rss": 100},
+		time.Unix(0, 0),
+	)
+}
+
+func docker(container string) telegraf.Metric {
+	return testutil.MustMetric(
+		"docker",
+		map[string]string{"container": container, "label_app": "web", "engine": "1"},
+		map[string]interface{}{"limit": 512, "usage": 10},
+		time.Unix(0, 0),
+	)
+}
+
+func joined(container string) telegraf.Metric {
+	return testutil.MustMetric(
+		"procstat",
+		map[string]string{"container": container, "label_app": "web"},
+		map[string]interface{}{"memory_rss": 100, "limit": 512},
+		time.Unix(0, 0),
+	)
+}
+
+func TestSourceFirst(t *testing.T) {
+	plugin := newJoin()
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(docker("a"))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{docker("a")}, actual)
+
+	actual = plugin.Apply(procstat("a"))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{joined("a")}, actual)
+}
+
+func TestHeldUntilSource(t *testing.T) {
+	plugin := newJoin()
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(procstat("a"), procstat("b"))
+	require.Len(t, actual, 0)
+
+	actual = plugin.Apply(docker("a"))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{joined("a"), docker("a")}, actual)
+	require.Len(t, plugin.pending, 1)
+}
+
+func TestSameBatch(t *testing.T) {
+	plugin := newJoin()
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(procstat("a"), docker("a"))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{joined("a"), docker("a")}, actual)
+}
+
+func TestWindow(t *testing.T) {
+	plugin := newJoin()
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(procstat("a"))
+	require.Len(t, actual, 0)
+
+	plugin.now = func() time.Time { return now.Add(10 * time.Second) }
+	actual = plugin.Apply()
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{procstat("a")}, actual)
+	require.Len(t, plugin.pending, 0)
+}
+
+func TestHeldMetricTracked(t *testing.T) {
+	plugin := newJoin()
+	require.NoError(t, plugin.Init())
+
+	var delivered bool
+	m, _ := metric.WithTracking(procstat("a"), func(info telegraf.DeliveryInfo) {
+		delivered = true
+	})
+
+	actual := plugin.Apply(m)
+	require.Len(t, actual, 0)
+	require.False(t, delivered)
+
+	actual = plugin.Apply(docker("a"))
+	require.Len(t, actual, 2)
+	require.Equal(t, m, actual[0])
+	actual[0].Accept()
+	require.True(t, delivered)
+}
+
+func TestStop(t *testing.T) {
+	plugin := newJoin()
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(procstat("a"), procstat("b"))
+	require.Len(t, actual, 0)
+
+	actual = plugin.Stop()
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{procstat("a"), procstat("b")}, actual)
+	require.Len(t, plugin.pending, 0)
+}
+
+func TestExpireRateLimited(t *testing.T) {
+	plugin := newJoin()
+	require.NoError(t, plugin.Init())
+
+	at := func(d time.Duration) { plugin.now = func() time.Time { return now.Add(d) } }
+
+	at(500 * time.Millisecond)
+	require.Len(t, plugin.Apply(procstat("a")), 0)
+
+	// The metric is not yet expired when the pending metrics are checked.
+	at(10 * time.Second)
+	require.Len(t, plugin.Apply(), 0)
+
+	// Expired, but the last check was less than a tenth of the window ago.
+	at(10*time.Second + 600*time.Millisecond)
+	require.Len(t, plugin.Apply(), 0)
+
+	at(11 * time.Second)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{procstat("a")}, plugin.Apply())
+}
+
+func TestSourceExpires(t *testing.T) {
+	plugin := newJoin()
+	require.NoError(t, plugin.Init())
+
+	plugin.Apply(docker("a"))
+
+	plugin.now = func() time.Time { return now.Add(10 * time.Second) }
+	actual := plugin.Apply(procstat("a"))
+	require.Len(t, actual, 0)
+	require.Len(t, plugin.sources, 0)
+}
+
+func TestDropSource(t *testing.T) {
+	plugin := newJoin()
+	plugin.DropSource = true
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(docker("a"), procstat("a"))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{joined("a")}, actual)
+}
+
+func TestPassThrough(t *testing.T) {
+	plugin := newJoin()
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"container": "a"},
+			map[string]interface{}{"usage": 1},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"procstat",
+			map[string]string{"pid": "1"},
+			map[string]interface{}{"memory_rss": 100},
+			time.Unix(0, 0),
+		),
+	}
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, input, actual)
+}
+
+func TestMultipleJoinTags(t *testing.T) {
+	plugin := newJoin()
+	plugin.Measurement = "snmp"
+	plugin.SourceMeasurement = "ifTable"
+	plugin.JoinTags = []string{"agent_host", "ifIndex"}
+	plugin.Tags = []string{"ifDescr"}
+	plugin.Fields = nil
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric(
+			"ifTable",
+			map[string]string{"agent_host": "10.0.0.1", "ifIndex": "1", "ifDescr": "eth0"},
+			map[string]interface{}{"ifSpeed": 1000},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"snmp",
+			map[string]string{"agent_host": "10.0.0.1", "ifIndex": "1"},
+			map[string]interface{}{"ifInOctets": 42},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"snmp",
+			map[string]string{"agent_host": "10.0.0.2", "ifIndex": "1"},
+			map[string]interface{}{"ifInOctets": 43},
+			time.Unix(0, 0),
+		),
+	}
+	expected := []telegraf.Metric{
+		input[0],
+		testutil.MustMetric(
+			"snmp",
+			map[string]string{"agent_host": "10.0.0.1", "ifIndex": "1", "ifDescr": "eth0"},
+			map[string]interface{}{"ifInOctets": 42},
+			time.Unix(0, 0),
+		),
+	}
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestInit(t *testing.T) {
+	var tests = []struct {
+		name      string
+		configure func(j *Join)
+	}{
+		{
+			name:      "no measurement",
+			configure: func(j *Join) { j.Measurement = "" },
+		},
+		{
+			name:      "same measurement",
+			configure: func(j *Join) { j.SourceMeasurement = j.Measurement },
+		},
+		{
+			name:      "no join tags",
+			configure: func(j *Join) { j.JoinTags = nil },
+		},
+		{
+			name: "nothing to copy",
+			configure: func(j *Join) {
+				j.Tags = nil
+				j.Fields = nil
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newJoin()
+			tt.configure(plugin)
+			require.Error(t, plugin.Init())
+		})
+	}
+}
@@ -0,0 +1,368 @@
+package lookup
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+const sampleConfig = `
+  ## Files containing the lookup table.  When a key is found in more than one
+  ## file the last file takes precedence.
+  files = ["/etc/telegraf/inventory.csv"]
+
+  ## Format of the files, either "csv" or "json".  By default the format is
+  ## selected using the file extension.
+  # format = ""
+
+  ## Tags of the metric to join on, the table must contain a column with the
+  ## same name for each tag.
+  key_tags = ["host"]
+
+  ## Columns to add as fields, all other columns are added as tags.
+  # field_columns = []
+
+  ## Interval to check the files for changes, the table is loaded again when
+  ## they are modified.  Set to 0 to disable.
+  # reload_interval = "1m"
+`
+
+// keySeparator joins the values of the key columns, it is not valid in tag
+// values sent by most inputs.
+const keySeparator = "\x00"
+
+type Lookup struct {
+	Files          []string          `toml:"files"`
+	Format         string            `toml:"format"`
+	KeyTags        []string          `toml:"key_tags"`
+	FieldColumns   []string          `toml:"field_columns"`
+	ReloadInterval internal.Duration `toml:"reload_interval"`
+
+	table     map[string]row
+	files     map[string]fileInfo
+	lastCheck time.Time
+}
+
+// row holds the columns of a table row to add to matching metrics.
+type row struct {
+	tags   map[string]string
+	fields map[string]interface{}
+}
+
+type fileInfo struct {
+	modTime time.Time
+	size    int64
+}
+
+func New() *Lookup {
+	return &Lookup{
+		ReloadInterval: internal.Duration{Duration: time.Minute},
+	}
+}
+
+func (l *Lookup) SampleConfig() string {
+	return sampleConfig
+}
+
+func (l *Lookup) Description() string {
+	return "Add tags and fields from a lookup table keyed by metric tags."
+}
+
+func (l *Lookup) Init() error {
+	if len(l.Files) == 0 {
+		return fmt.Errorf("no files configured")
+	}
+
+	if len(l.KeyTags) == 0 {
+		return fmt.Errorf("no key_tags configured")
+	}
+
+	switch l.Format {
+	case "", "csv", "json":
+	default:
+		return fmt.Errorf("unknown format %q", l.Format)
+	}
+
+	if err := l.load(); err != nil {
+		return err
+	}
+	l.lastCheck = time.Now()
+	return nil
+}
+
+func (l *Lookup) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	l.reload()
+
+	for _, m := range in {
+		key, ok := l.key(m)
+		if !ok {
+			continue
+		}
+
+		r, ok := l.table[key]
+		if !ok {
+			continue
+		}
+
+		for k, v := range r.tags {
+			m.AddTag(k, v)
+		}
+		for k, v := range r.fields {
+			m.AddField(k, v)
+		}
+	}
+	return in
+}
+
+// key returns the lookup key of the metric, it returns false if the metric
+// does not have all key tags.
+func (l *Lookup) key(m telegraf.Metric) (string, bool) {
+	values := make([]string, 0, len(l.KeyTags))
+	for _, tag := range l.KeyTags {
+		value, ok := m.GetTag(tag)
+		if !ok {
+			return "", false
+		}
+		values = append(values, value)
+	}
+	return strings.Join(values, keySeparator), true
+}
+
+// reload loads the table again if any of the files were modified since they
+// were last loaded.
+func (l *Lookup) reload() {
+	if l.ReloadInterval.Duration <= 0 {
+		return
+	}
+
+	now := time.Now()
+	if now.Sub(l.lastCheck) < l.ReloadInterval.Duration {
+		return
+	}
+	l.lastCheck = now
+
+	modified := false
+	for _, path := range l.Files {
+		info, err := os.Stat(path)
+		if err != nil {
+			log.Printf("E! [processors.lookup] could not check file: %v", err)
+			return
+		}
+		prev := l.files[path]
+		if !info.ModTime().Equal(prev.modTime) || info.Size() != prev.size {
+			modified = true
+		}
+	}
+	if !modified {
+		return
+	}
+
+	// When the table cannot be loaded, for example because a file is still
+	// being written, the previous table is kept and the load is attempted
+	// again on the next check.
+	if err := l.load(); err != nil {
+		log.Printf("E! [processors.lookup] could not reload table: %v", err)
+		return
+	}
+	log.Printf("I! [processors.lookup] reloaded table with %d rows", len(l.table))
+}
+
+// load reads all files into a new table.
+func (l *Lookup) load() error {
+	table := make(map[string]row)
+	files := make(map[string]fileInfo)
+	for _, path := range l.Files {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		files[path] = fileInfo{modTime: info.ModTime(), size: info.Size()}
+
+		buf, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		var records []map[string]interface{}
+		switch l.format(path) {
+		case "json":
+			records, err = parseJSON(buf)
+		default:
+			records, err = l.parseCSV(buf)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+
+		for i, record := range records {
+			key, r, err := l.row(record)
+			if err != nil {
+				return fmt.Errorf("%s: row %d: %v", path, i+1, err)
+			}
+			table[key] = r
+		}
+	}
+
+	l.table = table
+	l.files = files
+	return nil
+}
+
+func (l *Lookup) format(path string) string {
+	if l.Format != "" {
+		return l.Format
+	}
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		return "json"
+	}
+	return "csv"
+}
+
+// row splits a record into its key and the tags and fields to add.
+func (l *Lookup) row(record map[string]interface{}) (string, row, error) {
+	values := make([]string, 0, len(l.KeyTags))
+	for _, tag := range l.KeyTags {
+		value, ok := record[tag]
+		if !ok {
+			return "", row{}, fmt.Errorf("missing key column %q", tag)
+		}
+		values = append(values, toString(value))
+	}
+
+	r := row{
+		tags:   make(map[string]string),
+		fields: make(map[string]interface{}),
+	}
+	for column, value := range record {
+		if value == nil || value == "" || l.isKey(column) {
+			continue
+		}
+		if l.isField(column) {
+			if n, ok := value.(json.Number); ok {
+				r.fields[column] = convert(n.String())
+			} else {
+				r.fields[column] = value
+			}
+		} else {
+			r.tags[column] = toString(value)
+		}
+	}
+	return strings.Join(values, keySeparator), r, nil
+}
+
+func (l *Lookup) isKey(column string) bool {
+	for _, tag := range l.KeyTags {
+		if tag == column {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *Lookup) isField(column string) bool {
+	for _, field := range l.FieldColumns {
+		if field == column {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCSV parses a CSV file with a header row.  Values of field columns that
+// look like numbers or booleans are converted.
+func (l *Lookup) parseCSV(buf []byte) ([]map[string]interface{}, error) {
+	r := csv.NewReader(bytes.NewReader(buf))
+	r.TrimLeadingSpace = true
+	r.Comment = '#'
+
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	header := rows[0]
+	records := make([]map[string]interface{}, 0, len(rows)-1)
+	for _, values := range rows[1:] {
+		record := make(map[string]interface{}, len(header))
+		for i, column := range header {
+			if i >= len(values) {
+				break
+			}
+			if l.isField(column) {
+				record[column] = convert(values[i])
+			} else {
+				record[column] = values[i]
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// parseJSON parses a JSON array of objects, nested values are ignored.
+// Numbers are kept as json.Number so that field columns are converted the same
+// way as in CSV files.
+func parseJSON(buf []byte) ([]map[string]interface{}, error) {
+	var records []map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	if err := dec.Decode(&records); err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		for column, value := range record {
+			switch value.(type) {
+			case string, json.Number, bool:
+			default:
+				delete(record, column)
+			}
+		}
+	}
+	return records, nil
+}
+
+func convert(s string) interface{} {
+	if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return v
+	}
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return v
+	}
+	if v, err := strconv.ParseBool(s); err == nil {
+		return v
+	}
+	return s
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func init() {
+	processors.Add("lookup", func() telegraf.Processor {
+		return New()
+	})
+}
@@ -0,0 +1,206 @@
+package lookup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestApplyCSV(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lookup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	plugin := New()
+	plugin.Files = []string{writeFile(t, dir, "inventory.csv", `# inventory
+host,team,rack,weight
+server01,web,007,1.5
+server02,db,,2
+`)}
+	plugin.KeyTags = []string{"host"}
+	plugin.FieldColumns = []string{"weight"}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"host": "server01"},
+			map[string]interface{}{"usage": 42.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{"host": "server02"},
+			map[string]interface{}{"usage": 42.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{"host": "server03"},
+			map[string]interface{}{"usage": 42.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{},
+			map[string]interface{}{"usage": 42.0}, time.Unix(0, 0)),
+	}
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "server01", "team": "web", "rack": "007"},
+			map[string]interface{}{"usage": 42.0, "weight": 1.5}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "server02", "team": "db"},
+			map[string]interface{}{"usage": 42.0, "weight": int64(2)}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{"host": "server03"},
+			map[string]interface{}{"usage": 42.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{},
+			map[string]interface{}{"usage": 42.0}, time.Unix(0, 0)),
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestApplyJSONMultipleKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lookup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	plugin := New()
+	plugin.Files = []string{writeFile(t, dir, "ports.json", `[
+  {"device": "switch1", "port": "1", "owner": "alice", "vlan": 10, "speed": 1000, "load": 0.5},
+  {"device": "switch1", "port": "2", "owner": "bob", "nested": {"a": 1}}
+]`)}
+	plugin.KeyTags = []string{"device", "port"}
+	plugin.FieldColumns = []string{"speed", "load"}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric("interface", map[string]string{"device": "switch1", "port": "1"},
+			map[string]interface{}{"in_octets": 1}, time.Unix(0, 0)),
+		testutil.MustMetric("interface", map[string]string{"device": "switch1", "port": "2"},
+			map[string]interface{}{"in_octets": 1}, time.Unix(0, 0)),
+	}
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("interface",
+			map[string]string{"device": "switch1", "port": "1", "owner": "alice", "vlan": "10"},
+			map[string]interface{}{"in_octets": 1, "speed": int64(1000), "load": 0.5}, time.Unix(0, 0)),
+		testutil.MustMetric("interface",
+			map[string]string{"device": "switch1", "port": "2", "owner": "bob"},
+			map[string]interface{}{"in_octets": 1}, time.Unix(0, 0)),
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestFilePrecedence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lookup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	plugin := New()
+	plugin.Files = []string{
+		writeFile(t, dir, "a.csv", "host,team\nserver01,web\n"),
+		writeFile(t, dir, "b.csv", "host,team\nserver01,ops\n"),
+	}
+	plugin.KeyTags = []string{"host"}
+	require.NoError(t, plugin.Init())
+
+	m := testutil.MustMetric("cpu", map[string]string{"host": "server01"},
+		map[string]interface{}{"usage": 42.0}, time.Unix(0, 0))
+	plugin.Apply(m)
+
+	team, _ := m.GetTag("team")
+	require.Equal(t, "ops", team)
+}
+
+func TestReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lookup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := writeFile(t, dir, "inventory.csv", "host,team\nserver01,web\n")
+
+	plugin := New()
+	plugin.Files = []string{path}
+	plugin.KeyTags = []string{"host"}
+	require.NoError(t, plugin.Init())
+
+	writeFile(t, dir, "inventory.csv", "host,team\nserver01,database\n")
+	plugin.lastCheck = time.Now().Add(-time.Hour)
+
+	m := testutil.MustMetric("cpu", map[string]string{"host": "server01"},
+		map[string]interface{}{"usage": 42.0}, time.Unix(0, 0))
+	plugin.Apply(m)
+
+	team, _ := m.GetTag("team")
+	require.Equal(t, "database", team)
+
+	// An invalid file keeps the previous table.
+	writeFile(t, dir, "inventory.csv", "team\nweb\n")
+	plugin.lastCheck = time.Now().Add(-time.Hour)
+
+	m = testutil.MustMetric("cpu", map[string]string{"host": "server01"},
+		map[string]interface{}{"usage": 42.0}, time.Unix(0, 0))
+	plugin.Apply(m)
+
+	team, _ = m.GetTag("team")
+	require.Equal(t, "database", team)
+}
+
+func TestInit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lookup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := writeFile(t, dir, "inventory.csv", "host,team\nserver01,web\n")
+
+	var tests = []struct {
+		name    string
+		files   []string
+		keyTags []string
+		format  string
+	}{
+		{
+			name:    "no files",
+			keyTags: []string{"host"},
+		},
+		{
+			name:  "no key tags",
+			files: []string{path},
+		},
+		{
+			name:    "missing file",
+			files:   []string{filepath.Join(dir, "missing.csv")},
+			keyTags: []string{"host"},
+		},
+		{
+			name:    "missing key column",
+			files:   []string{path},
+			keyTags: []string{"device"},
+		},
+		{
+			name:    "unknown format",
+			files:   []string{path},
+			keyTags: []string{"host"},
+			format:  "xml",
+		},
+		{
+			name:    "invalid json",
+			files:   []string{path},
+			keyTags: []string{"host"},
+			format:  "json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := New()
+			plugin.Files = tt.files
+			plugin.KeyTags = tt.keyTags
+			plugin.Format = tt.format
+			require.Error(t, plugin.Init())
+		})
+	}
+}
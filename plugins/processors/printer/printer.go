@@ -14,6 +14,11 @@ type Printer struct {
 }
 
 var sampleConfig = `
+  ## Data format to print the metrics in.
+  ## Each data format has its own unique set of configuration options, read
+  ## more about them here:
+  ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_OUTPUT.md
+  # data_format = "influx"
 `
 
 func (p *Printer) SampleConfig() string {
@@ -24,6 +29,10 @@ func (p *Printer) Description() string {
 	return "Print all metrics that pass through this filter."
 }
 
+func (p *Printer) SetSerializer(serializer serializers.Serializer) {
+	p.serializer = serializer
+}
+
 func (p *Printer) Apply(in ...telegraf.Metric) []telegraf.Metric {
 	for _, metric := range in {
 		octets, err := p.serializer.Serialize(metric)
@@ -0,0 +1,251 @@
+package rate
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+const sampleConfig = `
+  ## Fields to compute the rate of, accepts glob patterns.  Fields that are
+  ## not numeric are ignored.
+  # fields = ["*"]
+
+  ## Only compute rates for metrics with the counter value type.
+  # counter_only = false
+
+  ## Suffix added to the field name to create the rate field.  If empty the
+  ## counter value is replaced by the rate.
+  # suffix = "_rate"
+
+  ## Remove the counter field, leaving only the rate.
+  # drop_original = false
+
+  ## The time unit of the rate, by default the rate is per second.
+  # period = "1s"
+
+  ## Width of the counters in bits, 32 or 64.  When set a decrease of a
+  ## counter that fits in this many bits is treated as a wrap around, when 0
+  ## any decrease is treated as a counter reset and no rate is emitted.
+  # counter_bits = 0
+
+  ## Forget series that have not been seen for this long.
+  # expire_after = "1h"
+`
+
+type Rate struct {
+	Fields       []string          `toml:"fields"`
+	CounterOnly  bool              `toml:"counter_only"`
+	Suffix       string            `toml:"suffix"`
+	DropOriginal bool              `toml:"drop_original"`
+	Period       internal.Duration `toml:"period"`
+	CounterBits  int               `toml:"counter_bits"`
+	ExpireAfter  internal.Duration `toml:"expire_after"`
+
+	fieldFilter filter.Filter
+	cache       map[uint64]*series
+	lastExpire  time.Time
+}
+
+// series holds the previous sample of each counter field of a series.
+type series struct {
+	samples  map[string]sample
+	lastSeen time.Time
+}
+
+// sample is a counter value and the time of the metric it was read from.
+// Fields can be missing from some metrics, so each has its own time.
+type sample struct {
+	value interface{}
+	time  time.Time
+}
+
+func New() *Rate {
+	return &Rate{
+		Fields:      []string{"*"},
+		Suffix:      "_rate",
+		Period:      internal.Duration{Duration: time.Second},
+		ExpireAfter: internal.Duration{Duration: time.Hour},
+	}
+}
+
+func (r *Rate) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *Rate) Description() string {
+	return "Compute the rate of change of counter fields."
+}
+
+func (r *Rate) Init() error {
+	var err error
+	r.fieldFilter, err = filter.Compile(r.Fields)
+	if err != nil {
+		return fmt.Errorf("could not compile fields: %v", err)
+	}
+
+	switch r.CounterBits {
+	case 0, 32, 64:
+	default:
+		return fmt.Errorf("counter_bits must be 0, 32 or 64, got %d", r.CounterBits)
+	}
+
+	if r.Period.Duration <= 0 {
+		r.Period.Duration = time.Second
+	}
+
+	r.cache = make(map[uint64]*series)
+	return nil
+}
+
+func (r *Rate) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := in[:0]
+	for _, m := range in {
+		if r.CounterOnly && m.Type() != telegraf.Counter {
+			out = append(out, m)
+			continue
+		}
+
+		r.process(m)
+
+		if len(m.FieldList()) == 0 {
+			m.Drop()
+			continue
+		}
+		out = append(out, m)
+	}
+
+	r.expire()
+	return out
+}
+
+// process replaces or adds the rate fields to the metric and records the
+// current counter values.
+func (r *Rate) process(m telegraf.Metric) {
+	id := m.HashID()
+	s, ok := r.cache[id]
+	if !ok {
+		s = &series{samples: make(map[string]sample)}
+		r.cache[id] = s
+	}
+	s.lastSeen = time.Now()
+
+	counters := make(map[string]interface{})
+	for _, field := range m.FieldList() {
+		if !r.fieldFilter.Match(field.Key) || !isNumeric(field.Value) {
+			continue
+		}
+		counters[field.Key] = field.Value
+	}
+
+	for key, value := range counters {
+		if r.Suffix == "" || r.DropOriginal {
+			m.RemoveField(key)
+		}
+
+		// Samples older than the previous sample do not update the state.
+		prev, ok := s.samples[key]
+		elapsed := m.Time().Sub(prev.time)
+		if ok && elapsed <= 0 {
+			continue
+		}
+		s.samples[key] = sample{value: value, time: m.Time()}
+		if !ok {
+			continue
+		}
+
+		delta, ok := r.delta(prev.value, value)
+		if !ok {
+			continue
+		}
+		rate := delta * float64(r.Period.Duration) / float64(elapsed)
+		m.AddField(key+r.Suffix, rate)
+	}
+}
+
+// delta returns the increase from prev to cur, it returns false if the
+// counter was reset.
+func (r *Rate) delta(prev, cur interface{}) (float64, bool) {
+	p, pok := toUint(prev)
+	c, cok := toUint(cur)
+	if pok && cok {
+		switch {
+		case c >= p:
+			return float64(c - p), true
+		case r.CounterBits == 32 && p <= math.MaxUint32:
+			return float64(uint32(c) - uint32(p)), true
+		case r.CounterBits == 64:
+			return float64(c - p), true
+		}
+		return 0, false
+	}
+
+	pf, cf := toFloat(prev), toFloat(cur)
+	if cf < pf {
+		return 0, false
+	}
+	return cf - pf, true
+}
+
+// expire removes series that have not been seen within the expire_after
+// duration.
+func (r *Rate) expire() {
+	if r.ExpireAfter.Duration <= 0 {
+		return
+	}
+
+	now := time.Now()
+	if now.Sub(r.lastExpire) < r.ExpireAfter.Duration {
+		return
+	}
+	r.lastExpire = now
+
+	for id, s := range r.cache {
+		if now.Sub(s.lastSeen) >= r.ExpireAfter.Duration {
+			delete(r.cache, id)
+		}
+	}
+}
+
+func isNumeric(value interface{}) bool {
+	switch value.(type) {
+	case int64, uint64, float64:
+		return true
+	}
+	return false
+}
+
+func toUint(value interface{}) (uint64, bool) {
+	switch v := value.(type) {
+	case uint64:
+		return v, true
+	case int64:
+		if v >= 0 {
+			return uint64(v), true
+		}
+	}
+	return 0, false
+}
+
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
+
+func init() {
+	processors.Add("rate", func() telegraf.Processor {
+		return New()
+	})
+}
@@ -0,0 +1,255 @@
+package rate
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newMetric(value interface{}, ts int64) telegraf.Metric {
+	return testutil.MustMetric(
+		"net",
+		map[string]string{
+			"interface": "eth0",
+		},
+		map[string]interface{}{
+			"bytes_recv": value,
+		},
+		time.Unix(ts, 0),
+	)
+}
+
+func TestRate(t *testing.T) {
+	r := New()
+	require.NoError(t, r.Init())
+
+	actual := r.Apply(newMetric(int64(100), 0))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{newMetric(int64(100), 0)}, actual)
+
+	actual = r.Apply(newMetric(int64(300), 10))
+	expected := newMetric(int64(300), 10)
+	expected.AddField("bytes_recv_rate", 20.0)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{expected}, actual)
+}
+
+func TestRateSeriesAreIndependent(t *testing.T) {
+	r := New()
+	require.NoError(t, r.Init())
+
+	other := testutil.MustMetric(
+		"net",
+		map[string]string{
+			"interface": "eth1",
+		},
+		map[string]interface{}{
+			"bytes_recv": int64(1000),
+		},
+		time.Unix(5, 0),
+	)
+
+	r.Apply(newMetric(int64(100), 0), other)
+	actual := r.Apply(newMetric(int64(200), 10))
+
+	rate, ok := actual[0].GetField("bytes_recv_rate")
+	require.True(t, ok)
+	require.Equal(t, 10.0, rate)
+}
+
+func TestRateCounterReset(t *testing.T) {
+	r := New()
+	require.NoError(t, r.Init())
+
+	r.Apply(newMetric(int64(100), 0))
+	actual := r.Apply(newMetric(int64(10), 10))
+	require.False(t, actual[0].HasField("bytes_recv_rate"))
+
+	actual = r.Apply(newMetric(int64(30), 20))
+	rate, ok := actual[0].GetField("bytes_recv_rate")
+	require.True(t, ok)
+	require.Equal(t, 2.0, rate)
+}
+
+func TestRateCounterWrap(t *testing.T) {
+	var tests = []struct {
+		name     string
+		bits     int
+		prev     interface{}
+		cur      interface{}
+		expected interface{}
+	}{
+		{
+			name:     "32 bit wrap",
+			bits:     32,
+			prev:     int64(math.MaxUint32 - 9),
+			cur:      int64(10),
+			expected: 2.0,
+		},
+		{
+			name: "32 bit counter larger than 32 bits is a reset",
+			bits: 32,
+			prev: int64(math.MaxUint32 + 10),
+			cur:  int64(10),
+		},
+		{
+			name:     "64 bit wrap",
+			bits:     64,
+			prev:     uint64(math.MaxUint64 - 9),
+			cur:      uint64(10),
+			expected: 2.0,
+		},
+		{
+			name: "reset without counter bits",
+			bits: 0,
+			prev: int64(math.MaxUint32 - 9),
+			cur:  int64(10),
+		},
+		{
+			name: "float decrease is a reset",
+			bits: 64,
+			prev: 10.5,
+			cur:  1.5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New()
+			r.CounterBits = tt.bits
+			require.NoError(t, r.Init())
+
+			r.Apply(newMetric(tt.prev, 0))
+			actual := r.Apply(newMetric(tt.cur, 10))
+
+			rate, _ := actual[0].GetField("bytes_recv_rate")
+			require.Equal(t, tt.expected, rate)
+		})
+	}
+}
+
+func TestRateReplaceField(t *testing.T) {
+	r := New()
+	r.Suffix = ""
+	require.NoError(t, r.Init())
+
+	actual := r.Apply(newMetric(uint64(100), 0))
+	require.Len(t, actual, 0)
+
+	actual = r.Apply(newMetric(uint64(160), 60))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{newMetric(1.0, 60)}, actual)
+}
+
+func TestRateDropOriginal(t *testing.T) {
+	r := New()
+	r.DropOriginal = true
+	r.Period.Duration = time.Minute
+	require.NoError(t, r.Init())
+
+	m := newMetric(2.5, 0)
+	m.AddField("errors", "none")
+	actual := r.Apply(m)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{
+		testutil.MustMetric(
+			"net",
+			map[string]string{
+				"interface": "eth0",
+			},
+			map[string]interface{}{
+				"errors": "none",
+			},
+			time.Unix(0, 0),
+		),
+	}, actual)
+
+	actual = r.Apply(newMetric(5.0, 30))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{
+		testutil.MustMetric(
+			"net",
+			map[string]string{
+				"interface": "eth0",
+			},
+			map[string]interface{}{
+				"bytes_recv_rate": 5.0,
+			},
+			time.Unix(30, 0),
+		),
+	}, actual)
+}
+
+func TestRateFields(t *testing.T) {
+	r := New()
+	r.Fields = []string{"bytes_*"}
+	require.NoError(t, r.Init())
+
+	m := newMetric(int64(10), 0)
+	m.AddField("drop_in", int64(10))
+	r.Apply(m)
+
+	m = newMetric(int64(20), 10)
+	m.AddField("drop_in", int64(20))
+	actual := r.Apply(m)
+
+	require.True(t, actual[0].HasField("bytes_recv_rate"))
+	require.False(t, actual[0].HasField("drop_in_rate"))
+}
+
+func TestRateCounterOnly(t *testing.T) {
+	r := New()
+	r.CounterOnly = true
+	require.NoError(t, r.Init())
+
+	counter := func(value int64, ts int64) telegraf.Metric {
+		m, err := metric.New("net", map[string]string{}, map[string]interface{}{"bytes_recv": value}, time.Unix(ts, 0), telegraf.Counter)
+		require.NoError(t, err)
+		return m
+	}
+
+	r.Apply(newMetric(int64(10), 0), counter(10, 0))
+	actual := r.Apply(newMetric(int64(20), 10), counter(20, 10))
+
+	require.False(t, actual[0].HasField("bytes_recv_rate"))
+	require.True(t, actual[1].HasField("bytes_recv_rate"))
+}
+
+func TestRateOutOfOrder(t *testing.T) {
+	r := New()
+	require.NoError(t, r.Init())
+
+	r.Apply(newMetric(int64(100), 10))
+	actual := r.Apply(newMetric(int64(50), 5))
+	require.False(t, actual[0].HasField("bytes_recv_rate"))
+
+	actual = r.Apply(newMetric(int64(200), 20))
+	rate, _ := actual[0].GetField("bytes_recv_rate")
+	require.Equal(t, 10.0, rate)
+}
+
+func TestRateMissingField(t *testing.T) {
+	r := New()
+	require.NoError(t, r.Init())
+
+	sample := func(fields map[string]interface{}, ts int64) telegraf.Metric {
+		return testutil.MustMetric("net", map[string]string{}, fields, time.Unix(ts, 0))
+	}
+
+	r.Apply(sample(map[string]interface{}{"a": int64(0), "b": int64(0)}, 0))
+
+	actual := r.Apply(sample(map[string]interface{}{"b": int64(10)}, 10))
+	rate, ok := actual[0].GetField("b_rate")
+	require.True(t, ok)
+	require.Equal(t, 1.0, rate)
+
+	actual = r.Apply(sample(map[string]interface{}{"a": int64(20)}, 20))
+	rate, ok = actual[0].GetField("a_rate")
+	require.True(t, ok)
+	require.Equal(t, 1.0, rate)
+}
+
+func TestRateInvalidCounterBits(t *testing.T) {
+	r := New()
+	r.CounterBits = 16
+	require.Error(t, r.Init())
+}
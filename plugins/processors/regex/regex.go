@@ -1,6 +1,7 @@
 package regex
 
 import (
+	"fmt"
 	"regexp"
 
 	"github.com/influxdata/telegraf"
@@ -8,9 +9,12 @@ import (
 )
 
 type Regex struct {
-	Tags       []converter
-	Fields     []converter
-	regexCache map[string]*regexp.Regexp
+	Tags         []converter
+	Fields       []converter
+	TagRename    []converter `toml:"tag_rename"`
+	FieldRename  []converter `toml:"field_rename"`
+	MetricRename []converter `toml:"metric_rename"`
+	regexCache   map[string]*regexp.Regexp
 }
 
 type converter struct {
@@ -19,6 +23,7 @@ type converter struct {
 	Replacement string
 	ResultKey   string
 	Append      bool
+	NamedGroups bool `toml:"named_groups"`
 }
 
 const sampleConfig = `
@@ -50,6 +55,29 @@ const sampleConfig = `
   #   pattern = ".*category=(\\w+).*"
   #   replacement = "${1}"
   #   result_key = "search_category"
+
+  ## With named_groups each named subgroup of the pattern is written into
+  ## its own tag or field named after the subgroup, replacement and
+  ## result_key are not used.
+  # [[processors.regex.fields]]
+  #   key = "request"
+  #   pattern = "^/api/(?P<api_resource>\\w+)/(?P<api_id>\\d+)"
+  #   named_groups = true
+
+  ## Rename tag and field keys matching the pattern, existing tags and
+  ## fields with the new key are overwritten.
+  # [[processors.regex.tag_rename]]
+  #   pattern = "^resp_(\\w+)$"
+  #   replacement = "response_${1}"
+
+  # [[processors.regex.field_rename]]
+  #   pattern = "^search_(\\w+)$"
+  #   replacement = "${1}"
+
+  ## Rename measurements matching the pattern.
+  # [[processors.regex.metric_rename]]
+  #   pattern = "^nginx_(\\w+)$"
+  #   replacement = "http_${1}"
 `
 
 func NewRegex() *Regex {
@@ -66,10 +94,38 @@ func (r *Regex) Description() string {
 	return "Transforms tag and field values with regex pattern"
 }
 
+func (r *Regex) Init() error {
+	for _, converters := range [][]converter{r.Tags, r.Fields, r.TagRename, r.FieldRename, r.MetricRename} {
+		for _, c := range converters {
+			regex, err := regexp.Compile(c.Pattern)
+			if err != nil {
+				return fmt.Errorf("invalid pattern %q: %v", c.Pattern, err)
+			}
+			if c.NamedGroups && !hasNamedGroups(regex) {
+				return fmt.Errorf("pattern %q has no named subgroups", c.Pattern)
+			}
+			r.regexCache[c.Pattern] = regex
+		}
+	}
+	return nil
+}
+
 func (r *Regex) Apply(in ...telegraf.Metric) []telegraf.Metric {
 	for _, metric := range in {
 		for _, converter := range r.Tags {
 			if value, ok := metric.GetTag(converter.Key); ok {
+				if converter.NamedGroups {
+					for key, newValue := range r.namedGroups(converter, value) {
+						if converter.Append {
+							if v, ok := metric.GetTag(key); ok {
+								newValue = v + newValue
+							}
+						}
+						metric.AddTag(key, newValue)
+					}
+					continue
+				}
+
 				if key, newValue := r.convert(converter, value); newValue != "" {
 					if converter.Append {
 						if v, ok := metric.GetTag(key); ok {
@@ -85,23 +141,61 @@ func (r *Regex) Apply(in ...telegraf.Metric) []telegraf.Metric {
 			if value, ok := metric.GetField(converter.Key); ok {
 				switch value := value.(type) {
 				case string:
+					if converter.NamedGroups {
+						for key, newValue := range r.namedGroups(converter, value) {
+							metric.AddField(key, newValue)
+						}
+						continue
+					}
+
 					if key, newValue := r.convert(converter, value); newValue != "" {
 						metric.AddField(key, newValue)
 					}
 				}
 			}
 		}
+
+		for _, converter := range r.TagRename {
+			for _, tag := range copyTagKeys(metric) {
+				if key, ok := r.rename(converter, tag); ok {
+					value, _ := metric.GetTag(tag)
+					metric.RemoveTag(tag)
+					metric.AddTag(key, value)
+				}
+			}
+		}
+
+		for _, converter := range r.FieldRename {
+			for _, field := range copyFieldKeys(metric) {
+				if key, ok := r.rename(converter, field); ok {
+					value, _ := metric.GetField(field)
+					metric.RemoveField(field)
+					metric.AddField(key, value)
+				}
+			}
+		}
+
+		for _, converter := range r.MetricRename {
+			if name, ok := r.rename(converter, metric.Name()); ok {
+				metric.SetName(name)
+			}
+		}
 	}
 
 	return in
 }
 
-func (r *Regex) convert(c converter, src string) (string, string) {
-	regex, compiled := r.regexCache[c.Pattern]
+func (r *Regex) regex(pattern string) *regexp.Regexp {
+	regex, compiled := r.regexCache[pattern]
 	if !compiled {
-		regex = regexp.MustCompile(c.Pattern)
-		r.regexCache[c.Pattern] = regex
+		regex = regexp.MustCompile(pattern)
+		r.regexCache[pattern] = regex
 	}
+	return regex
+}
+
+func (r *Regex) convert(c converter, src string) (string, string) {
+	regex := r.regex(c.Pattern)
 
 	value := ""
 	if c.ResultKey == "" || regex.MatchString(src) {
@@ -115,6 +209,65 @@ func (r *Regex) convert(c converter, src string) (string, string) {
 	return c.Key, value
 }
 
+// namedGroups returns the text of each named subgroup matched in src by its
+// name, nothing is returned if the pattern does not match.
+func (r *Regex) namedGroups(c converter, src string) map[string]string {
+	regex := r.regex(c.Pattern)
+
+	match := regex.FindStringSubmatchIndex(src)
+	if match == nil {
+		return nil
+	}
+
+	groups := make(map[string]string)
+	for i, name := range regex.SubexpNames() {
+		if name == "" || match[2*i] < 0 {
+			continue
+		}
+		groups[name] = src[match[2*i]:match[2*i+1]]
+	}
+	return groups
+}
+
+// rename returns the new name for a key or name matching the pattern.
+func (r *Regex) rename(c converter, src string) (string, bool) {
+	regex := r.regex(c.Pattern)
+	if !regex.MatchString(src) {
+		return "", false
+	}
+
+	name := regex.ReplaceAllString(src, c.Replacement)
+	if name == "" || name == src {
+		return "", false
+	}
+	return name, true
+}
+
+func hasNamedGroups(regex *regexp.Regexp) bool {
+	for _, name := range regex.SubexpNames() {
+		if name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func copyTagKeys(metric telegraf.Metric) []string {
+	keys := make([]string, 0, len(metric.TagList()))
+	for _, tag := range metric.TagList() {
+		keys = append(keys, tag.Key)
+	}
+	return keys
+}
+
+func copyFieldKeys(metric telegraf.Metric) []string {
+	keys := make([]string, 0, len(metric.FieldList()))
+	for _, field := range metric.FieldList() {
+		keys = append(keys, field.Key)
+	}
+	return keys
+}
+
 func init() {
 	processors.Add("regex", func() telegraf.Processor {
 		return NewRegex()
@@ -1,6 +1,7 @@
 package regex
 
 import (
+	"regexp"
 	"testing"
 	"time"
 
@@ -261,6 +262,192 @@ func TestNoMatches(t *testing.T) {
 	}
 }
 
+func TestNamedGroups(t *testing.T) {
+	regex := NewRegex()
+	regex.Tags = []converter{
+		{
+			Key:         "resp_code",
+			Pattern:     "^(?P<resp_class>\\d)(?P<resp_detail>\\d\\d)$",
+			NamedGroups: true,
+		},
+	}
+	regex.Fields = []converter{
+		{
+			Key:         "request",
+			Pattern:     "^/(?P<api>api)?/?(?P<resource>\\w+)/(?:\\?category=(?P<category>\\w+))?",
+			NamedGroups: true,
+		},
+		{
+			Key:         "request",
+			Pattern:     "^/admin/(?P<admin_page>\\w+)",
+			NamedGroups: true,
+		},
+	}
+	assert.NoError(t, regex.Init())
+
+	processed := regex.Apply(newM2())
+
+	expectedFields := map[string]interface{}{
+		"request":       "/api/search/?category=plugins&q=regex&sort=asc",
+		"api":           "api",
+		"resource":      "search",
+		"category":      "plugins",
+		"ignore_number": int64(200),
+		"ignore_bool":   true,
+	}
+	expectedTags := map[string]string{
+		"verb":        "GET",
+		"resp_code":   "200",
+		"resp_class":  "2",
+		"resp_detail": "00",
+	}
+
+	assert.Equal(t, expectedFields, processed[0].Fields())
+	assert.Equal(t, expectedTags, processed[0].Tags())
+
+	processed = regex.Apply(newM1())
+
+	expectedFields = map[string]interface{}{
+		"request":  "/users/42/",
+		"resource": "users",
+	}
+	assert.Equal(t, expectedFields, processed[0].Fields(), "Should skip unmatched subgroups")
+}
+
+func TestRenames(t *testing.T) {
+	tests := []struct {
+		message        string
+		regex          *Regex
+		expectedName   string
+		expectedTags   map[string]string
+		expectedFields map[string]interface{}
+	}{
+		{
+			message: "Should rename matching tags",
+			regex: &Regex{
+				TagRename: []converter{
+					{
+						Pattern:     "^resp_(\\w+)$",
+						Replacement: "response_${1}",
+					},
+				},
+			},
+			expectedName: "access_log",
+			expectedTags: map[string]string{
+				"verb":          "GET",
+				"response_code": "200",
+			},
+			expectedFields: map[string]interface{}{
+				"request": "/users/42/",
+			},
+		},
+		{
+			message: "Should overwrite existing tags",
+			regex: &Regex{
+				TagRename: []converter{
+					{
+						Pattern:     "^resp_code$",
+						Replacement: "verb",
+					},
+				},
+			},
+			expectedName: "access_log",
+			expectedTags: map[string]string{
+				"verb": "200",
+			},
+			expectedFields: map[string]interface{}{
+				"request": "/users/42/",
+			},
+		},
+		{
+			message: "Should rename matching fields",
+			regex: &Regex{
+				FieldRename: []converter{
+					{
+						Pattern:     "^request$",
+						Replacement: "url",
+					},
+				},
+			},
+			expectedName: "access_log",
+			expectedTags: map[string]string{
+				"verb":      "GET",
+				"resp_code": "200",
+			},
+			expectedFields: map[string]interface{}{
+				"url": "/users/42/",
+			},
+		},
+		{
+			message: "Should rename matching measurements",
+			regex: &Regex{
+				MetricRename: []converter{
+					{
+						Pattern:     "^access_(\\w+)$",
+						Replacement: "http_${1}",
+					},
+					{
+						Pattern:     "^cpu$",
+						Replacement: "processor",
+					},
+				},
+			},
+			expectedName: "http_log",
+			expectedTags: map[string]string{
+				"verb":      "GET",
+				"resp_code": "200",
+			},
+			expectedFields: map[string]interface{}{
+				"request": "/users/42/",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		regex := test.regex
+		regex.regexCache = make(map[string]*regexp.Regexp)
+		assert.NoError(t, regex.Init())
+
+		processed := regex.Apply(newM1())
+
+		assert.Equal(t, test.expectedName, processed[0].Name(), test.message)
+		assert.Equal(t, test.expectedTags, processed[0].Tags(), test.message)
+		assert.Equal(t, test.expectedFields, processed[0].Fields(), test.message)
+	}
+}
+
+func TestInit(t *testing.T) {
+	tests := []struct {
+		message string
+		regex   *Regex
+	}{
+		{
+			message: "Should fail on invalid pattern",
+			regex: &Regex{
+				Tags: []converter{{Key: "verb", Pattern: "("}},
+			},
+		},
+		{
+			message: "Should fail on named_groups without named subgroups",
+			regex: &Regex{
+				Fields: []converter{{Key: "request", Pattern: "^/(\\w+)", NamedGroups: true}},
+			},
+		},
+		{
+			message: "Should fail on invalid rename pattern",
+			regex: &Regex{
+				MetricRename: []converter{{Pattern: "[", Replacement: "x"}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		regex := test.regex
+		regex.regexCache = make(map[string]*regexp.Regexp)
+		assert.Error(t, regex.Init(), test.message)
+	}
+}
+
 func BenchmarkConversions(b *testing.B) {
 	regex := NewRegex()
 	regex.Tags = []converter{
@@ -0,0 +1,295 @@
+package reverse_dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+const sampleConfig = `
+  ## How long a resolved name is kept in the cache.
+  # cache_ttl = "24h"
+
+  ## How long a failed lookup, or a lookup that found no name, is kept in the
+  ## cache.
+  # negative_cache_ttl = "1m"
+
+  ## Maximum time to wait for lookups while processing a batch of metrics.
+  ## When a lookup does not complete in time the metric is emitted unchanged,
+  ## the lookup continues in the background and its result is used for later
+  ## metrics.
+  # lookup_timeout = "3s"
+
+  ## Maximum number of DNS requests in flight at the same time.
+  # max_parallel_lookups = 10
+
+  [[processors.reverse_dns.lookup]]
+    ## Name of the tag containing the IP address
+    tag = "source"
+
+    ## Name of the field containing the IP address
+    # field = "source"
+
+    ## Tag, or field when the address is read from a field, to store the
+    ## hostname in.  By default the IP address is replaced.
+    dest = "source_name"
+`
+
+// maxPending limits the number of lookups waiting for a free worker.
+const maxPending = 1000
+
+// instances numbers the plugin instances so that each has its own statistics.
+var instances int64
+
+type ReverseDNS struct {
+	CacheTTL           internal.Duration `toml:"cache_ttl"`
+	NegativeCacheTTL   internal.Duration `toml:"negative_cache_ttl"`
+	LookupTimeout      internal.Duration `toml:"lookup_timeout"`
+	MaxParallelLookups int               `toml:"max_parallel_lookups"`
+	Lookups            []Lookup          `toml:"lookup"`
+
+	resolver resolver
+	queue    chan string
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+
+	mu        sync.Mutex
+	entries   map[string]entry
+	pending   map[string]chan struct{}
+	lastClean time.Time
+
+	cacheHits      selfstat.Stat
+	cacheMisses    selfstat.Stat
+	lookupTimeouts selfstat.Stat
+}
+
+// Lookup describes where to find an IP address in a metric and where to add
+// the hostname.
+type Lookup struct {
+	Tag   string `toml:"tag"`
+	Field string `toml:"field"`
+	Dest  string `toml:"dest"`
+}
+
+type resolver interface {
+	LookupAddr(ctx context.Context, addr string) ([]string, error)
+}
+
+// entry is a cached lookup result, the name is empty if the lookup failed or
+// found no name.
+type entry struct {
+	name    string
+	expires time.Time
+}
+
+func New() *ReverseDNS {
+	return &ReverseDNS{
+		CacheTTL:           internal.Duration{Duration: 24 * time.Hour},
+		NegativeCacheTTL:   internal.Duration{Duration: time.Minute},
+		LookupTimeout:      internal.Duration{Duration: 3 * time.Second},
+		MaxParallelLookups: 10,
+		resolver:           net.DefaultResolver,
+	}
+}
+
+func (r *ReverseDNS) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *ReverseDNS) Description() string {
+	return "Add the hostname of IP addresses using reverse DNS lookups."
+}
+
+func (r *ReverseDNS) Init() error {
+	for _, lookup := range r.Lookups {
+		if (lookup.Tag == "") == (lookup.Field == "") {
+			return fmt.Errorf("lookup must have exactly one of tag or field")
+		}
+	}
+
+	if r.MaxParallelLookups <= 0 {
+		return fmt.Errorf("max_parallel_lookups must be positive")
+	}
+
+	r.entries = make(map[string]entry)
+	r.pending = make(map[string]chan struct{})
+	r.lastClean = time.Now()
+
+	tags := map[string]string{
+		"instance": strconv.FormatInt(atomic.AddInt64(&instances, 1)-1, 10),
+	}
+	r.cacheHits = selfstat.Register("reverse_dns", "cache_hits", tags)
+	r.cacheMisses = selfstat.Register("reverse_dns", "cache_misses", tags)
+	r.lookupTimeouts = selfstat.Register("reverse_dns", "lookup_timeouts", tags)
+
+	r.queue = make(chan string, maxPending)
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	for i := 0; i < r.MaxParallelLookups; i++ {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			for addr := range r.queue {
+				r.lookup(addr)
+			}
+		}()
+	}
+	return nil
+}
+
+// Stop cancels the lookups in progress and stops the workers.
+func (r *ReverseDNS) Stop() []telegraf.Metric {
+	r.cancel()
+	close(r.queue)
+	r.wg.Wait()
+	return nil
+}
+
+func (r *ReverseDNS) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	deadline := time.Now().Add(r.LookupTimeout.Duration)
+	for _, m := range in {
+		for _, lookup := range r.Lookups {
+			r.apply(m, lookup, deadline)
+		}
+	}
+
+	r.clean()
+	return in
+}
+
+func (r *ReverseDNS) apply(m telegraf.Metric, lookup Lookup, deadline time.Time) {
+	var addr string
+	if lookup.Tag != "" {
+		addr, _ = m.GetTag(lookup.Tag)
+	} else if v, ok := m.GetField(lookup.Field); ok {
+		addr, _ = v.(string)
+	}
+	if addr == "" {
+		return
+	}
+
+	name, ok := r.resolve(addr, deadline)
+	if !ok {
+		return
+	}
+
+	if lookup.Tag != "" {
+		dest := lookup.Dest
+		if dest == "" {
+			dest = lookup.Tag
+		}
+		m.AddTag(dest, name)
+		return
+	}
+
+	dest := lookup.Dest
+	if dest == "" {
+		dest = lookup.Field
+	}
+	m.AddField(dest, name)
+}
+
+// resolve returns the hostname of the address, waiting for a lookup to
+// complete until the deadline.  It returns false if the address could not be
+// resolved in time.
+func (r *ReverseDNS) resolve(addr string, deadline time.Time) (string, bool) {
+	r.mu.Lock()
+	if e, ok := r.entries[addr]; ok && time.Now().Before(e.expires) {
+		r.mu.Unlock()
+		r.cacheHits.Incr(1)
+		return e.name, e.name != ""
+	}
+	r.cacheMisses.Incr(1)
+
+	done, ok := r.pending[addr]
+	if !ok {
+		select {
+		case r.queue <- addr:
+		default:
+			// Too many lookups are waiting for a worker.
+			r.mu.Unlock()
+			return "", false
+		}
+		done = make(chan struct{})
+		r.pending[addr] = done
+	}
+	r.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		r.lookupTimeouts.Incr(1)
+		return "", false
+	}
+
+	r.mu.Lock()
+	e := r.entries[addr]
+	r.mu.Unlock()
+	return e.name, e.name != ""
+}
+
+// lookup resolves the address and stores the result in the cache, then
+// closes the done channel of the pending lookup.
+func (r *ReverseDNS) lookup(addr string) {
+	ctx, cancel := context.WithTimeout(r.ctx, r.LookupTimeout.Duration)
+	names, err := r.resolver.LookupAddr(ctx, addr)
+	cancel()
+
+	var name string
+	if err == nil && len(names) > 0 {
+		name = strings.TrimSuffix(names[0], ".")
+	}
+
+	ttl := r.CacheTTL.Duration
+	if name == "" {
+		ttl = r.NegativeCacheTTL.Duration
+	}
+
+	r.mu.Lock()
+	r.entries[addr] = entry{name: name, expires: time.Now().Add(ttl)}
+	done := r.pending[addr]
+	delete(r.pending, addr)
+	r.mu.Unlock()
+	close(done)
+}
+
+// clean removes expired entries from the cache.
+func (r *ReverseDNS) clean() {
+	now := time.Now()
+
+	interval := r.CacheTTL.Duration
+	if r.NegativeCacheTTL.Duration < interval {
+		interval = r.NegativeCacheTTL.Duration
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if now.Sub(r.lastClean) < interval {
+		return
+	}
+	r.lastClean = now
+
+	for addr, e := range r.entries {
+		if !now.Before(e.expires) {
+			delete(r.entries, addr)
+		}
+	}
+}
+
+func init() {
+	processors.Add("reverse_dns", func() telegraf.Processor {
+		return New()
+	})
+}
@@ -0,0 +1,248 @@
+package reverse_dns
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeResolver struct {
+	sync.Mutex
+	names   map[string]string
+	delay   time.Duration
+	lookups int
+}
+
+func (f *fakeResolver) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	f.Lock()
+	f.lookups++
+	f.Unlock()
+
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	name, ok := f.names[addr]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return []string{name}, nil
+}
+
+func (f *fakeResolver) count() int {
+	f.Lock()
+	defer f.Unlock()
+	return f.lookups
+}
+
+func newTestPlugin(resolver *fakeResolver, lookups ...Lookup) *ReverseDNS {
+	plugin := New()
+	plugin.resolver = resolver
+	plugin.Lookups = lookups
+	return plugin
+}
+
+func TestApply(t *testing.T) {
+	resolver := &fakeResolver{
+		names: map[string]string{
+			"192.0.2.1": "host1.example.org.",
+			"192.0.2.2": "host2.example.org.",
+		},
+	}
+	plugin := newTestPlugin(resolver,
+		Lookup{Tag: "source", Dest: "source_name"},
+		Lookup{Field: "dest"},
+	)
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	input := []telegraf.Metric{
+		testutil.MustMetric(
+			"conntrack",
+			map[string]string{"source": "192.0.2.1"},
+			map[string]interface{}{"dest": "192.0.2.2"},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"conntrack",
+			map[string]string{"source": "192.0.2.3"},
+			map[string]interface{}{"value": 42},
+			time.Unix(0, 0),
+		),
+	}
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"conntrack",
+			map[string]string{
+				"source":      "192.0.2.1",
+				"source_name": "host1.example.org",
+			},
+			map[string]interface{}{"dest": "host2.example.org"},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"conntrack",
+			map[string]string{"source": "192.0.2.3"},
+			map[string]interface{}{"value": 42},
+			time.Unix(0, 0),
+		),
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestCache(t *testing.T) {
+	resolver := &fakeResolver{
+		names: map[string]string{"192.0.2.1": "host1.example.org."},
+	}
+	plugin := newTestPlugin(resolver, Lookup{Tag: "source"})
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	hits := plugin.cacheHits.Get()
+	misses := plugin.cacheMisses.Get()
+
+	for i := 0; i < 3; i++ {
+		m := testutil.MustMetric("conntrack", map[string]string{"source": "192.0.2.1"},
+			map[string]interface{}{"value": 42}, time.Unix(0, 0))
+		plugin.Apply(m)
+		require.Equal(t, map[string]string{"source": "host1.example.org"}, m.Tags())
+	}
+
+	require.Equal(t, 1, resolver.count())
+	require.Equal(t, int64(2), plugin.cacheHits.Get()-hits)
+	require.Equal(t, int64(1), plugin.cacheMisses.Get()-misses)
+}
+
+func TestCacheExpire(t *testing.T) {
+	resolver := &fakeResolver{
+		names: map[string]string{"192.0.2.1": "host1.example.org."},
+	}
+	plugin := newTestPlugin(resolver, Lookup{Tag: "source"})
+	plugin.CacheTTL.Duration = time.Millisecond
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	m := testutil.MustMetric("conntrack", map[string]string{"source": "192.0.2.1"},
+		map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	plugin.Apply(m)
+	time.Sleep(2 * time.Millisecond)
+	plugin.Apply(m.Copy())
+
+	require.Equal(t, 2, resolver.count())
+}
+
+func TestNegativeCacheExpire(t *testing.T) {
+	resolver := &fakeResolver{}
+	plugin := newTestPlugin(resolver, Lookup{Tag: "source"})
+	plugin.NegativeCacheTTL.Duration = time.Millisecond
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	m := testutil.MustMetric("conntrack", map[string]string{"source": "192.0.2.1"},
+		map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	plugin.Apply(m)
+	time.Sleep(2 * time.Millisecond)
+	plugin.Apply(m.Copy())
+
+	require.Equal(t, 2, resolver.count())
+}
+
+func TestLookupTimeout(t *testing.T) {
+	resolver := &fakeResolver{
+		names: map[string]string{"192.0.2.1": "host1.example.org."},
+		delay: 50 * time.Millisecond,
+	}
+	plugin := newTestPlugin(resolver, Lookup{Tag: "source"})
+	plugin.LookupTimeout.Duration = 10 * time.Millisecond
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	timeouts := plugin.lookupTimeouts.Get()
+
+	m := testutil.MustMetric("conntrack", map[string]string{"source": "192.0.2.1"},
+		map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	start := time.Now()
+	plugin.Apply(m)
+	require.True(t, time.Since(start) < 50*time.Millisecond)
+	require.Equal(t, map[string]string{"source": "192.0.2.1"}, m.Tags())
+	require.Equal(t, int64(1), plugin.lookupTimeouts.Get()-timeouts)
+}
+
+func TestLookupContinuesInBackground(t *testing.T) {
+	resolver := &fakeResolver{
+		names: map[string]string{"192.0.2.1": "host1.example.org."},
+		delay: 20 * time.Millisecond,
+	}
+	plugin := newTestPlugin(resolver, Lookup{Tag: "source"})
+	plugin.LookupTimeout.Duration = 100 * time.Millisecond
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	// Time out the first batch without waiting for the lookup.
+	m := testutil.MustMetric("conntrack", map[string]string{"source": "192.0.2.1"},
+		map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	plugin.resolve("192.0.2.1", time.Now())
+
+	plugin.Apply(m)
+	require.Equal(t, map[string]string{"source": "host1.example.org"}, m.Tags())
+	require.Equal(t, 1, resolver.count())
+}
+
+func TestStopCancelsLookups(t *testing.T) {
+	resolver := &fakeResolver{delay: time.Minute}
+	plugin := newTestPlugin(resolver, Lookup{Tag: "source"})
+	plugin.LookupTimeout.Duration = time.Minute
+	require.NoError(t, plugin.Init())
+
+	plugin.resolve("192.0.2.1", time.Now())
+
+	start := time.Now()
+	require.Empty(t, plugin.Stop())
+	require.True(t, time.Since(start) < time.Minute)
+}
+
+func TestStatsPerInstance(t *testing.T) {
+	resolver := &fakeResolver{
+		names: map[string]string{"192.0.2.1": "host1.example.org."},
+	}
+	plugin1 := newTestPlugin(resolver, Lookup{Tag: "source"})
+	require.NoError(t, plugin1.Init())
+	defer plugin1.Stop()
+	plugin2 := newTestPlugin(resolver, Lookup{Tag: "source"})
+	require.NoError(t, plugin2.Init())
+	defer plugin2.Stop()
+
+	misses := plugin2.cacheMisses.Get()
+
+	m := testutil.MustMetric("conntrack", map[string]string{"source": "192.0.2.1"},
+		map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	plugin1.Apply(m)
+
+	require.NotEqual(t, plugin1.cacheMisses.Tags()["instance"], plugin2.cacheMisses.Tags()["instance"])
+	require.Equal(t, misses, plugin2.cacheMisses.Get())
+}
+
+func TestInit(t *testing.T) {
+	plugin := New()
+	plugin.Lookups = []Lookup{{Tag: "source", Field: "source"}}
+	require.Error(t, plugin.Init())
+
+	plugin = New()
+	plugin.Lookups = []Lookup{{}}
+	require.Error(t, plugin.Init())
+
+	plugin = New()
+	plugin.MaxParallelLookups = 0
+	require.Error(t, plugin.Init())
+}
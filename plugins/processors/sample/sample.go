@@ -0,0 +1,155 @@
+package sample
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+const sampleConfig = `
+  ## Percentage of series to keep.  Series are selected by a hash of the
+  ## measurement name and tags, so the same series are always kept.
+  # series_percent = 100.0
+
+  ## Probability of keeping each metric, between 0 and 1.  When less than 1
+  ## the rate is added to the kept metrics as a field so that values can be
+  ## rescaled.
+  # sample_rate = 1.0
+
+  ## Name of the field containing the sample rate.
+  # sample_rate_field = "sample_rate"
+
+  ## Maximum number of metrics per second for each measurement, metrics over
+  ## the limit are dropped.  0 for no limit.
+  # rate_limit = 0.0
+
+  ## Number of metrics for each measurement that can be emitted at once
+  ## before the rate limit applies, by default the rate limit.
+  # burst = 0
+`
+
+type Sample struct {
+	SeriesPercent   float64 `toml:"series_percent"`
+	SampleRate      float64 `toml:"sample_rate"`
+	SampleRateField string  `toml:"sample_rate_field"`
+	RateLimit       float64 `toml:"rate_limit"`
+	Burst           int     `toml:"burst"`
+
+	buckets map[string]*bucket
+	rand    func() float64
+	now     func() time.Time
+}
+
+// bucket is a token bucket limiting the rate of a measurement.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func New() *Sample {
+	return &Sample{
+		SeriesPercent:   100,
+		SampleRate:      1,
+		SampleRateField: "sample_rate",
+		rand:            rand.Float64,
+		now:             time.Now,
+	}
+}
+
+func (s *Sample) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Sample) Description() string {
+	return "Sample metrics by series or at random and limit the rate of metrics."
+}
+
+func (s *Sample) Init() error {
+	if s.SeriesPercent < 0 || s.SeriesPercent > 100 {
+		return fmt.Errorf("series_percent must be between 0 and 100, got %v", s.SeriesPercent)
+	}
+
+	if s.SampleRate <= 0 || s.SampleRate > 1 {
+		return fmt.Errorf("sample_rate must be greater than 0 and at most 1, got %v", s.SampleRate)
+	}
+
+	if s.RateLimit < 0 || s.Burst < 0 {
+		return fmt.Errorf("rate_limit and burst must not be negative")
+	}
+	if s.Burst == 0 {
+		s.Burst = int(s.RateLimit)
+	}
+	if s.RateLimit > 0 && s.Burst < 1 {
+		s.Burst = 1
+	}
+
+	s.buckets = make(map[string]*bucket)
+	return nil
+}
+
+func (s *Sample) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := in[:0]
+	for _, m := range in {
+		if !s.keep(m) {
+			m.Drop()
+			continue
+		}
+
+		if s.SampleRate < 1 {
+			m.AddField(s.SampleRateField, s.SampleRate)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func (s *Sample) keep(m telegraf.Metric) bool {
+	if s.SeriesPercent < 100 {
+		if float64(m.HashID()%10000) >= s.SeriesPercent*100 {
+			return false
+		}
+	}
+
+	if s.SampleRate < 1 && s.rand() >= s.SampleRate {
+		return false
+	}
+
+	if s.RateLimit > 0 {
+		return s.take(m.Name())
+	}
+	return true
+}
+
+// take removes a token from the bucket of the measurement, it returns false
+// if the bucket is empty.
+func (s *Sample) take(name string) bool {
+	now := s.now()
+	b, ok := s.buckets[name]
+	if !ok {
+		b = &bucket{tokens: float64(s.Burst), last: now}
+		s.buckets[name] = b
+	}
+
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * s.RateLimit
+		if b.tokens > float64(s.Burst) {
+			b.tokens = float64(s.Burst)
+		}
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func init() {
+	processors.Add("sample", func() telegraf.Processor {
+		return New()
+	})
+}
@@ -0,0 +1,118 @@
+package sample
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newMetric(name string, id int) telegraf.Metric {
+	return testutil.MustMetric(
+		name,
+		map[string]string{"id": fmt.Sprint(id)},
+		map[string]interface{}{"value": 1},
+		time.Unix(0, 0),
+	)
+}
+
+func TestSeriesPercent(t *testing.T) {
+	plugin := New()
+	plugin.SeriesPercent = 25
+	require.NoError(t, plugin.Init())
+
+	kept := make(map[uint64]bool)
+	for i := 0; i < 1000; i++ {
+		for _, m := range plugin.Apply(newMetric("cpu", i)) {
+			kept[m.HashID()] = true
+		}
+	}
+	require.InDelta(t, 250, len(kept), 50)
+
+	// The same series are kept every time.
+	for i := 0; i < 1000; i++ {
+		m := newMetric("cpu", i)
+		id := m.HashID()
+		require.Equal(t, kept[id], len(plugin.Apply(m)) == 1)
+	}
+}
+
+func TestSampleRate(t *testing.T) {
+	plugin := New()
+	plugin.SampleRate = 0.5
+	values := []float64{0.1, 0.7, 0.4, 0.9}
+	plugin.rand = func() float64 {
+		v := values[0]
+		values = values[1:]
+		return v
+	}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(newMetric("cpu", 0), newMetric("cpu", 1), newMetric("cpu", 2), newMetric("cpu", 3))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"id": "0"},
+			map[string]interface{}{"value": 1, "sample_rate": 0.5}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{"id": "2"},
+			map[string]interface{}{"value": 1, "sample_rate": 0.5}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestRateLimit(t *testing.T) {
+	now := time.Unix(0, 0)
+	plugin := New()
+	plugin.RateLimit = 2
+	plugin.now = func() time.Time { return now }
+	require.NoError(t, plugin.Init())
+
+	var in []telegraf.Metric
+	for i := 0; i < 5; i++ {
+		in = append(in, newMetric("cpu", i), newMetric("mem", i))
+	}
+	actual := plugin.Apply(in...)
+	require.Len(t, actual, 4)
+
+	now = now.Add(500 * time.Millisecond)
+	actual = plugin.Apply(newMetric("cpu", 0), newMetric("cpu", 1))
+	require.Len(t, actual, 1)
+
+	// Tokens do not accumulate past the burst.
+	now = now.Add(time.Hour)
+	in = nil
+	for i := 0; i < 5; i++ {
+		in = append(in, newMetric("cpu", i))
+	}
+	actual = plugin.Apply(in...)
+	require.Len(t, actual, 2)
+}
+
+func TestBurst(t *testing.T) {
+	plugin := New()
+	plugin.RateLimit = 0.1
+	require.NoError(t, plugin.Init())
+	require.Equal(t, 1, plugin.Burst)
+
+	plugin = New()
+	plugin.RateLimit = 1
+	plugin.Burst = 10
+	require.NoError(t, plugin.Init())
+	require.Equal(t, 10, plugin.Burst)
+}
+
+func TestInit(t *testing.T) {
+	plugin := New()
+	plugin.SeriesPercent = 101
+	require.Error(t, plugin.Init())
+
+	plugin = New()
+	plugin.SampleRate = 0
+	require.Error(t, plugin.Init())
+
+	plugin = New()
+	plugin.RateLimit = -1
+	require.Error(t, plugin.Init())
+}
@@ -0,0 +1,163 @@
+package tag_limit
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+const sampleConfig = `
+  ## Maximum number of tags to keep, 0 for no limit.
+  # limit = 10
+
+  ## Tags to keep first when there are more tags than the limit, in order of
+  ## priority.  The remaining tags are kept in alphabetical order.
+  # keep = ["host"]
+
+  ## Maximum length in bytes of tag keys and values, longer keys and values are
+  ## truncated.  0 for no limit.
+  # max_key_length = 0
+  # max_value_length = 0
+
+  ## Characters allowed in tag keys and values as the contents of a regular
+  ## expression character class, other characters are replaced.  By default
+  ## all characters are allowed.
+  # allowed_characters = "a-zA-Z0-9_.-"
+
+  ## String to replace characters that are not allowed with.
+  # replacement = "_"
+`
+
+type TagLimit struct {
+	Limit             int      `toml:"limit"`
+	Keep              []string `toml:"keep"`
+	MaxKeyLength      int      `toml:"max_key_length"`
+	MaxValueLength    int      `toml:"max_value_length"`
+	AllowedCharacters string   `toml:"allowed_characters"`
+	Replacement       string   `toml:"replacement"`
+
+	disallowed *regexp.Regexp
+	priority   map[string]int
+}
+
+func New() *TagLimit {
+	return &TagLimit{
+		Replacement: "_",
+	}
+}
+
+func (t *TagLimit) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *TagLimit) Description() string {
+	return "Limit the number and length of tags and the characters they contain."
+}
+
+func (t *TagLimit) Init() error {
+	if t.Limit < 0 || t.MaxKeyLength < 0 || t.MaxValueLength < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+
+	if t.AllowedCharacters != "" {
+		var err error
+		t.disallowed, err = regexp.Compile("[^" + t.AllowedCharacters + "]")
+		if err != nil {
+			return fmt.Errorf("invalid allowed_characters: %v", err)
+		}
+	}
+
+	t.priority = make(map[string]int, len(t.Keep))
+	for i, key := range t.Keep {
+		t.priority[key] = i
+	}
+	return nil
+}
+
+func (t *TagLimit) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range in {
+		t.apply(m)
+	}
+	return in
+}
+
+func (t *TagLimit) apply(m telegraf.Metric) {
+	tagList := m.TagList()
+	if len(tagList) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(tagList))
+	tags := make([]*telegraf.Tag, 0, len(tagList))
+	changed := false
+	for _, tag := range tagList {
+		keys = append(keys, tag.Key)
+
+		key := t.normalize(tag.Key, t.MaxKeyLength)
+		value := t.normalize(tag.Value, t.MaxValueLength)
+		if key != tag.Key || value != tag.Value {
+			changed = true
+		}
+		if key == "" || value == "" {
+			continue
+		}
+		tags = append(tags, &telegraf.Tag{Key: key, Value: value})
+	}
+
+	if t.Limit > 0 && len(tags) > t.Limit {
+		// The tag list is sorted by key, the sort is stable so tags with the
+		// same priority stay in alphabetical order.
+		sort.SliceStable(tags, func(i, j int) bool {
+			return t.rank(tags[i].Key) < t.rank(tags[j].Key)
+		})
+		tags = tags[:t.Limit]
+		changed = true
+	}
+
+	if !changed {
+		return
+	}
+
+	for _, key := range keys {
+		m.RemoveTag(key)
+	}
+	for _, tag := range tags {
+		m.AddTag(tag.Key, tag.Value)
+	}
+}
+
+// rank returns the position of the key in the keep list, keys not in the list
+// are ranked after all keys in the list.
+func (t *TagLimit) rank(key string) int {
+	if i, ok := t.priority[key]; ok {
+		return i
+	}
+	return len(t.Keep)
+}
+
+// normalize replaces disallowed characters and truncates s to at most max
+// bytes without splitting a multibyte character.
+func (t *TagLimit) normalize(s string, max int) string {
+	if t.disallowed != nil {
+		s = t.disallowed.ReplaceAllLiteralString(s, t.Replacement)
+	}
+
+	if max > 0 && len(s) > max {
+		n := max
+		for n > 0 && !utf8.RuneStart(s[n]) {
+			n--
+		}
+		s = s[:n]
+	}
+	return s
+}
+
+func init() {
+	processors.Add("tag_limit", func() telegraf.Processor {
+		return New()
+	})
+}
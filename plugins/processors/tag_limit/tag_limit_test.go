@@ -0,0 +1,145 @@
+package tag_limit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimit(t *testing.T) {
+	plugin := New()
+	plugin.Limit = 3
+	plugin.Keep = []string{"region", "host"}
+	require.NoError(t, plugin.Init())
+
+	input := testutil.MustMetric(
+		"cpu",
+		map[string]string{
+			"a":      "1",
+			"b":      "2",
+			"c":      "3",
+			"host":   "server01",
+			"region": "us-east-1",
+		},
+		map[string]interface{}{"value": 42},
+		time.Unix(0, 0),
+	)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{
+				"a":      "1",
+				"host":   "server01",
+				"region": "us-east-1",
+			},
+			map[string]interface{}{"value": 42},
+			time.Unix(0, 0),
+		),
+	}
+
+	actual := plugin.Apply(input)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestUnderLimit(t *testing.T) {
+	plugin := New()
+	plugin.Limit = 3
+	require.NoError(t, plugin.Init())
+
+	input := testutil.MustMetric(
+		"cpu",
+		map[string]string{"a": "1", "b": "2"},
+		map[string]interface{}{"value": 42},
+		time.Unix(0, 0),
+	)
+	expected := []telegraf.Metric{input.Copy()}
+
+	actual := plugin.Apply(input)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestNormalize(t *testing.T) {
+	plugin := New()
+	plugin.AllowedCharacters = "a-zA-Z0-9_.-"
+	plugin.MaxKeyLength = 8
+	plugin.MaxValueLength = 5
+	require.NoError(t, plugin.Init())
+
+	input := testutil.MustMetric(
+		"http",
+		map[string]string{
+			"url path":        "/index.html",
+			"a_very_long_key": "x",
+			"status":          "ok",
+			"emoji":           "☃",
+		},
+		map[string]interface{}{"value": 42},
+		time.Unix(0, 0),
+	)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"http",
+			map[string]string{
+				"url_path": "_inde",
+				"a_very_l": "x",
+				"status":   "ok",
+				"emoji":    "_",
+			},
+			map[string]interface{}{"value": 42},
+			time.Unix(0, 0),
+		),
+	}
+
+	actual := plugin.Apply(input)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestTruncateMultibyte(t *testing.T) {
+	plugin := New()
+	plugin.MaxValueLength = 4
+	require.NoError(t, plugin.Init())
+
+	require.Equal(t, "ab", plugin.normalize("ab☃", 4))
+	require.Equal(t, "ab☃", plugin.normalize("ab☃", 5))
+}
+
+func TestRemoveEmpty(t *testing.T) {
+	plugin := New()
+	plugin.AllowedCharacters = "a-z"
+	plugin.Replacement = ""
+	require.NoError(t, plugin.Init())
+
+	input := testutil.MustMetric(
+		"cpu",
+		map[string]string{"host": "server", "id": "1234"},
+		map[string]interface{}{"value": 42},
+		time.Unix(0, 0),
+	)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"host": "server"},
+			map[string]interface{}{"value": 42},
+			time.Unix(0, 0),
+		),
+	}
+
+	actual := plugin.Apply(input)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestInit(t *testing.T) {
+	plugin := New()
+	plugin.AllowedCharacters = "z-a"
+	require.Error(t, plugin.Init())
+
+	plugin = New()
+	plugin.Limit = -1
+	require.Error(t, plugin.Init())
+}
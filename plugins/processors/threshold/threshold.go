@@ -0,0 +1,288 @@
+package threshold
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+const sampleConfig = `
+  ## Measurement name of the emitted alert metrics.
+  # measurement = "alert"
+
+  ## Prefix of the name, field and state tags added to the alert metrics, it
+  ## avoids replacing tags of the metric with the same names.
+  # tag_prefix = "alert_"
+
+  ## Series that are not ok and did not report the field for this long are
+  ## forgotten, a later value starts again from the ok state.
+  # state_expiration = "1h"
+
+  ## Rules evaluated for each metric, the state is kept separately for each
+  ## series and rule.
+  [[processors.threshold.rule]]
+    ## Field to check, metrics without the field or with a non numeric value
+    ## are ignored.
+    field = "usage_user"
+
+    ## Whether values "above" or "below" the thresholds are a problem.
+    # direction = "above"
+
+    ## Thresholds of the warn and critical states, at least one is required.
+    warn = 80.0
+    critical = 90.0
+
+    ## Amount the value must move back past a threshold before leaving the
+    ## state.
+    # hysteresis = 0.0
+
+    ## Time a new state must hold before it is reported.
+    # duration = "0s"
+`
+
+const (
+	directionAbove = "above"
+	directionBelow = "below"
+)
+
+// Alert states, ordered by severity.
+const (
+	stateOK = iota
+	stateWarn
+	stateCritical
+)
+
+var stateNames = []string{"ok", "warn", "critical"}
+
+type Threshold struct {
+	Measurement     string            `toml:"measurement"`
+	TagPrefix       string            `toml:"tag_prefix"`
+	StateExpiration internal.Duration `toml:"state_expiration"`
+	Rules           []*Rule           `toml:"rule"`
+
+	states     map[key]*state
+	lastExpire time.Time
+}
+
+// Rule defines the thresholds of a field.
+type Rule struct {
+	Field      string            `toml:"field"`
+	Direction  string            `toml:"direction"`
+	Warn       *float64          `toml:"warn"`
+	Critical   *float64          `toml:"critical"`
+	Hysteresis float64           `toml:"hysteresis"`
+	Duration   internal.Duration `toml:"duration"`
+}
+
+// key identifies the state of a rule for a series.
+type key struct {
+	series uint64
+	rule   int
+}
+
+// state is the reported state of a series and the state it is changing to.
+type state struct {
+	current  int
+	pending  int
+	since    time.Time
+	lastSeen time.Time
+}
+
+func New() *Threshold {
+	return &Threshold{
+		Measurement:     "alert",
+		TagPrefix:       "alert_",
+		StateExpiration: internal.Duration{Duration: time.Hour},
+	}
+}
+
+func (t *Threshold) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *Threshold) Description() string {
+	return "Emit alert metrics when fields cross thresholds."
+}
+
+func (t *Threshold) Init() error {
+	if t.Measurement == "" {
+		return fmt.Errorf("measurement is required")
+	}
+
+	if t.StateExpiration.Duration <= 0 {
+		return fmt.Errorf("state_expiration must be positive")
+	}
+
+	for _, r := range t.Rules {
+		if r.Field == "" {
+			return fmt.Errorf("rule field is required")
+		}
+		if r.Direction == "" {
+			r.Direction = directionAbove
+		}
+		if r.Direction != directionAbove && r.Direction != directionBelow {
+			return fmt.Errorf("unknown direction %q for field %q", r.Direction, r.Field)
+		}
+		if r.Warn == nil && r.Critical == nil {
+			return fmt.Errorf("warn or critical threshold required for field %q", r.Field)
+		}
+		if r.Warn != nil && r.Critical != nil && r.exceeds(*r.Warn, *r.Critical) {
+			return fmt.Errorf("warn threshold of field %q is beyond the critical threshold", r.Field)
+		}
+		if r.Hysteresis < 0 {
+			return fmt.Errorf("hysteresis of field %q must not be negative", r.Field)
+		}
+	}
+
+	t.states = make(map[key]*state)
+	t.lastExpire = time.Now()
+	return nil
+}
+
+func (t *Threshold) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := time.Now()
+	t.expire(now)
+
+	var alerts []telegraf.Metric
+	for _, m := range in {
+		var id uint64
+		for i, r := range t.Rules {
+			value, ok := getNumber(m, r.Field)
+			if !ok {
+				continue
+			}
+			if id == 0 {
+				id = m.HashID()
+			}
+
+			k := key{series: id, rule: i}
+			s, ok := t.states[k]
+			if !ok {
+				s = &state{}
+			}
+			s.lastSeen = now
+
+			target := r.state(value, s.current)
+			switch {
+			case target == s.current:
+				s.pending = s.current
+			case target != s.pending:
+				s.pending = target
+				s.since = m.Time()
+			}
+
+			if s.pending != s.current && m.Time().Sub(s.since) >= r.Duration.Duration {
+				if alert := t.alert(m, r, value, s.current, s.pending); alert != nil {
+					alerts = append(alerts, alert)
+				}
+				s.current = s.pending
+			}
+
+			// Only series that are not ok are kept so that the number of
+			// states is bounded by the number of problems.
+			if s.current == stateOK && s.pending == stateOK {
+				delete(t.states, k)
+			} else {
+				t.states[k] = s
+			}
+		}
+	}
+	return append(in, alerts...)
+}
+
+// alert creates the alert metric for a state change of the series of m.
+func (t *Threshold) alert(m telegraf.Metric, r *Rule, value float64, from, to int) telegraf.Metric {
+	tags := m.Tags()
+	tags[t.TagPrefix+"name"] = m.Name()
+	tags[t.TagPrefix+"field"] = r.Field
+	tags[t.TagPrefix+"state"] = stateNames[to]
+
+	fields := map[string]interface{}{
+		"value":          value,
+		"previous_state": stateNames[from],
+	}
+
+	alert, err := metric.New(t.Measurement, tags, fields, m.Time())
+	if err != nil {
+		log.Printf("E! [processors.threshold] could not create alert: %v", err)
+		return nil
+	}
+	return alert
+}
+
+// expire removes the states of series that did not report the field of the
+// rule within the expiration, so that series that stop reporting while not ok
+// are not kept forever.
+func (t *Threshold) expire(now time.Time) {
+	if now.Sub(t.lastExpire) < t.StateExpiration.Duration/10 {
+		return
+	}
+	t.lastExpire = now
+
+	for k, s := range t.states {
+		if now.Sub(s.lastSeen) >= t.StateExpiration.Duration {
+			delete(t.states, k)
+		}
+	}
+}
+
+// state returns the state for the value given the current state, the
+// thresholds of the current and lower states are moved by the hysteresis so
+// that a value must clearly leave the state.
+func (r *Rule) state(value float64, current int) int {
+	if r.Critical != nil && r.reached(value, *r.Critical, current >= stateCritical) {
+		return stateCritical
+	}
+	if r.Warn != nil && r.reached(value, *r.Warn, current >= stateWarn) {
+		return stateWarn
+	}
+	return stateOK
+}
+
+// reached returns true if the value reached the threshold.
+func (r *Rule) reached(value, threshold float64, active bool) bool {
+	if active {
+		if r.Direction == directionBelow {
+			threshold += r.Hysteresis
+		} else {
+			threshold -= r.Hysteresis
+		}
+	}
+	return value == threshold || r.exceeds(value, threshold)
+}
+
+// exceeds returns true if a is beyond b in the direction of the rule.
+func (r *Rule) exceeds(a, b float64) bool {
+	if r.Direction == directionBelow {
+		return a < b
+	}
+	return a > b
+}
+
+func getNumber(m telegraf.Metric, field string) (float64, bool) {
+	value, ok := m.GetField(field)
+	if !ok {
+		return 0, false
+	}
+
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func init() {
+	processors.Add("threshold", func() telegraf.Processor {
+		return New()
+	})
+}
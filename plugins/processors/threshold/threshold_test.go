@@ -0,0 +1,266 @@
+package threshold
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func float(v float64) *float64 {
+	return &v
+}
+
+func cpu(value float64, sec int64) telegraf.Metric {
+	return testutil.MustMetric(
+		"cpu",
+		map[string]string{"cpu": "cpu0"},
+		map[string]interface{}{"usage_user": value},
+		time.Unix(sec, 0),
+	)
+}
+
+func alert(state, previous string, value float64, sec int64) telegraf.Metric {
+	return testutil.MustMetric(
+		"alert",
+		map[string]string{
+			"cpu":         "cpu0",
+			"alert_name":  "cpu",
+			"alert_field": "usage_user",
+			"alert_state": state,
+		},
+		map[string]interface{}{
+			"value":          value,
+			"previous_state": previous,
+		},
+		time.Unix(sec, 0),
+	)
+}
+
+// apply applies the processor to each metric and returns the alerts.
+func apply(plugin *Threshold, metrics ...telegraf.Metric) []telegraf.Metric {
+	var alerts []telegraf.Metric
+	for _, m := range metrics {
+		out := plugin.Apply(m)
+		alerts = append(alerts, out[1:]...)
+	}
+	return alerts
+}
+
+func TestTransitions(t *testing.T) {
+	plugin := New()
+	plugin.Rules = []*Rule{
+		{Field: "usage_user", Warn: float(80), Critical: float(90)},
+	}
+	require.NoError(t, plugin.Init())
+
+	alerts := apply(plugin,
+		cpu(50, 0),
+		cpu(85, 1),
+		cpu(86, 2),
+		cpu(95, 3),
+		cpu(85, 4),
+		cpu(10, 5),
+		cpu(10, 6),
+	)
+
+	expected := []telegraf.Metric{
+		alert("warn", "ok", 85, 1),
+		alert("critical", "warn", 95, 3),
+		alert("warn", "critical", 85, 4),
+		alert("ok", "warn", 10, 5),
+	}
+	testutil.RequireMetricsEqual(t, expected, alerts)
+	require.Len(t, plugin.states, 0)
+}
+
+func TestBelow(t *testing.T) {
+	plugin := New()
+	plugin.Rules = []*Rule{
+		{Field: "usage_user", Direction: "below", Critical: float(5)},
+	}
+	require.NoError(t, plugin.Init())
+
+	alerts := apply(plugin, cpu(50, 0), cpu(5, 1), cpu(6, 2))
+
+	expected := []telegraf.Metric{
+		alert("critical", "ok", 5, 1),
+		alert("ok", "critical", 6, 2),
+	}
+	testutil.RequireMetricsEqual(t, expected, alerts)
+}
+
+func TestHysteresis(t *testing.T) {
+	plugin := New()
+	plugin.Rules = []*Rule{
+		{Field: "usage_user", Warn: float(80), Hysteresis: 5},
+	}
+	require.NoError(t, plugin.Init())
+
+	alerts := apply(plugin, cpu(81, 0), cpu(79, 1), cpu(76, 2), cpu(74, 3))
+
+	expected := []telegraf.Metric{
+		alert("warn", "ok", 81, 0),
+		alert("ok", "warn", 74, 3),
+	}
+	testutil.RequireMetricsEqual(t, expected, alerts)
+}
+
+func TestDuration(t *testing.T) {
+	plugin := New()
+	plugin.Rules = []*Rule{
+		{
+			Field:    "usage_user",
+			Warn:     float(80),
+			Duration: internal.Duration{Duration: 10 * time.Second},
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	alerts := apply(plugin,
+		cpu(85, 0),
+		cpu(85, 5),
+		cpu(50, 6),
+		cpu(85, 10),
+		cpu(85, 15),
+		cpu(85, 20),
+		cpu(85, 30),
+	)
+
+	expected := []telegraf.Metric{
+		alert("warn", "ok", 85, 20),
+	}
+	testutil.RequireMetricsEqual(t, expected, alerts)
+}
+
+func TestSeries(t *testing.T) {
+	plugin := New()
+	plugin.Rules = []*Rule{
+		{Field: "usage_user", Warn: float(80)},
+	}
+	require.NoError(t, plugin.Init())
+
+	other := testutil.MustMetric(
+		"cpu",
+		map[string]string{"cpu": "cpu1"},
+		map[string]interface{}{"usage_user": 10.0},
+		time.Unix(1, 0),
+	)
+
+	alerts := apply(plugin, cpu(85, 0), other)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{alert("warn", "ok", 85, 0)}, alerts)
+}
+
+func TestApplyPassesMetrics(t *testing.T) {
+	plugin := New()
+	plugin.Rules = []*Rule{
+		{Field: "usage_user", Warn: float(80)},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		cpu(85, 0),
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"cpu": "cpu1"},
+			map[string]interface{}{"usage_user": "high"},
+			time.Unix(0, 0),
+		),
+	}
+	output := plugin.Apply(input...)
+	require.Len(t, output, 3)
+	require.Equal(t, input, output[:2])
+}
+
+func TestTagPrefix(t *testing.T) {
+	plugin := New()
+	plugin.Rules = []*Rule{
+		{Field: "usage_user", Warn: float(80)},
+	}
+	require.NoError(t, plugin.Init())
+
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{"cpu": "cpu0", "state": "running"},
+		map[string]interface{}{"usage_user": 85.0},
+		time.Unix(0, 0),
+	)
+
+	alerts := apply(plugin, m)
+	require.Len(t, alerts, 1)
+	require.Equal(t, map[string]string{
+		"cpu":         "cpu0",
+		"state":       "running",
+		"alert_name":  "cpu",
+		"alert_field": "usage_user",
+		"alert_state": "warn",
+	}, alerts[0].Tags())
+}
+
+func TestStateExpiration(t *testing.T) {
+	plugin := New()
+	plugin.Rules = []*Rule{
+		{Field: "usage_user", Warn: float(80)},
+	}
+	require.NoError(t, plugin.Init())
+
+	alerts := apply(plugin, cpu(85, 0))
+	require.Len(t, alerts, 1)
+	require.Len(t, plugin.states, 1)
+
+	// Age the states past the expiration.
+	past := time.Now().Add(-2 * time.Hour)
+	for _, s := range plugin.states {
+		s.lastSeen = past
+	}
+	plugin.lastExpire = past
+
+	alerts = apply(plugin, cpu(85, 10))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{alert("warn", "ok", 85, 10)}, alerts)
+}
+
+func TestInit(t *testing.T) {
+	var tests = []struct {
+		name string
+		rule *Rule
+	}{
+		{
+			name: "no field",
+			rule: &Rule{Warn: float(1)},
+		},
+		{
+			name: "no threshold",
+			rule: &Rule{Field: "a"},
+		},
+		{
+			name: "unknown direction",
+			rule: &Rule{Field: "a", Warn: float(1), Direction: "up"},
+		},
+		{
+			name: "warn above critical",
+			rule: &Rule{Field: "a", Warn: float(2), Critical: float(1)},
+		},
+		{
+			name: "warn below critical",
+			rule: &Rule{Field: "a", Warn: float(1), Critical: float(2), Direction: "below"},
+		},
+		{
+			name: "negative hysteresis",
+			rule: &Rule{Field: "a", Warn: float(1), Hysteresis: -1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := New()
+			plugin.Rules = []*Rule{tt.rule}
+			require.Error(t, plugin.Init())
+		})
+	}
+
+	plugin := New()
+	plugin.StateExpiration.Duration = 0
+	require.Error(t, plugin.Init())
+}
@@ -0,0 +1,190 @@
+package timestamp
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+const sampleConfig = `
+  ## Source of the timestamp:
+  ##   metric  - the timestamp of the metric
+  ##   arrival - the time the metric is processed
+  ##   tag     - the tag named by source_key
+  ##   field   - the field named by source_key
+  ## When the tag or field is missing or cannot be parsed the timestamp of
+  ## the metric is used.
+  # source = "metric"
+
+  ## Tag or field containing the timestamp.
+  # source_key = ""
+
+  ## Format of the tag or field, either "unix", "unix_ms", "unix_us",
+  ## "unix_ns" or a Go "reference time" layout such as
+  ## "2006-01-02T15:04:05Z07:00".
+  # source_format = "unix"
+
+  ## Time zone of layouts without a zone, "Local" for the system time zone.
+  # source_timezone = "UTC"
+
+  ## Duration added to the timestamp, for example to correct the clock of a
+  ## device, can be negative.
+  # offset = "0s"
+
+  ## Align the timestamp to a multiple of the alignment interval since the
+  ## epoch by rounding to the nearest multiple or truncating to the previous
+  ## one, alignment is done after adding the offset:
+  ##   none     - do not align
+  ##   round    - round to the nearest multiple
+  ##   truncate - truncate to the previous multiple
+  # alignment = "none"
+  # alignment_interval = "10s"
+`
+
+const (
+	sourceMetric  = "metric"
+	sourceArrival = "arrival"
+	sourceTag     = "tag"
+	sourceField   = "field"
+
+	alignNone     = "none"
+	alignRound    = "round"
+	alignTruncate = "truncate"
+)
+
+type Timestamp struct {
+	Source            string            `toml:"source"`
+	SourceKey         string            `toml:"source_key"`
+	SourceFormat      string            `toml:"source_format"`
+	SourceTimezone    string            `toml:"source_timezone"`
+	Offset            internal.Duration `toml:"offset"`
+	Alignment         string            `toml:"alignment"`
+	AlignmentInterval internal.Duration `toml:"alignment_interval"`
+
+	now func() time.Time
+}
+
+func New() *Timestamp {
+	return &Timestamp{
+		Source:         sourceMetric,
+		SourceFormat:   "unix",
+		SourceTimezone: "UTC",
+		Alignment:      alignNone,
+		now:            time.Now,
+	}
+}
+
+func (t *Timestamp) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *Timestamp) Description() string {
+	return "Replace, shift and align the timestamp of metrics."
+}
+
+func (t *Timestamp) Init() error {
+	switch t.Source {
+	case "":
+		t.Source = sourceMetric
+	case sourceMetric, sourceArrival:
+	case sourceTag, sourceField:
+		if t.SourceKey == "" {
+			return fmt.Errorf("source_key is required for source %q", t.Source)
+		}
+		if t.SourceFormat == "" {
+			return fmt.Errorf("source_format is required for source %q", t.Source)
+		}
+		if _, err := time.LoadLocation(t.SourceTimezone); err != nil {
+			return fmt.Errorf("invalid source_timezone %q: %v", t.SourceTimezone, err)
+		}
+	default:
+		return fmt.Errorf("unknown source %q", t.Source)
+	}
+
+	switch t.Alignment {
+	case "":
+		t.Alignment = alignNone
+	case alignNone:
+	case alignRound, alignTruncate:
+		if t.AlignmentInterval.Duration <= 0 {
+			return fmt.Errorf("alignment_interval must be positive")
+		}
+	default:
+		return fmt.Errorf("unknown alignment %q", t.Alignment)
+	}
+	return nil
+}
+
+func (t *Timestamp) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := t.now()
+	for _, m := range in {
+		tm := m.Time()
+		switch t.Source {
+		case sourceArrival:
+			tm = now
+		case sourceTag, sourceField:
+			parsed, err := t.parse(m)
+			if err != nil {
+				log.Printf("D! [processors.timestamp] could not get timestamp of metric %q: %v", m.Name(), err)
+			} else {
+				tm = parsed
+			}
+		}
+
+		tm = tm.Add(t.Offset.Duration)
+
+		switch t.Alignment {
+		case alignRound:
+			tm = truncate(tm.Add(t.AlignmentInterval.Duration/2), t.AlignmentInterval.Duration)
+		case alignTruncate:
+			tm = truncate(tm, t.AlignmentInterval.Duration)
+		}
+
+		m.SetTime(tm)
+	}
+	return in
+}
+
+// parse returns the timestamp in the source tag or field.
+func (t *Timestamp) parse(m telegraf.Metric) (time.Time, error) {
+	var value interface{}
+	var ok bool
+	if t.Source == sourceTag {
+		value, ok = m.GetTag(t.SourceKey)
+	} else {
+		value, ok = m.GetField(t.SourceKey)
+	}
+	if !ok {
+		return time.Time{}, fmt.Errorf("%s %q not found", t.Source, t.SourceKey)
+	}
+
+	switch v := value.(type) {
+	case uint64:
+		value = int64(v)
+	case string, int64, float64:
+	default:
+		return time.Time{}, fmt.Errorf("unsupported type %T", value)
+	}
+
+	return internal.ParseTimestampWithLocation(value, t.SourceFormat, t.SourceTimezone)
+}
+
+// truncate returns the time rounded down to a multiple of d since the epoch.
+func truncate(tm time.Time, d time.Duration) time.Time {
+	ns := tm.UnixNano()
+	rem := ns % int64(d)
+	if rem < 0 {
+		rem += int64(d)
+	}
+	return time.Unix(0, ns-rem).In(tm.Location())
+}
+
+func init() {
+	processors.Add("timestamp", func() telegraf.Processor {
+		return New()
+	})
+}
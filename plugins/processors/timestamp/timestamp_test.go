@@ -0,0 +1,200 @@
+package timestamp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply(t *testing.T) {
+	arrival := time.Unix(1500000000, 0)
+
+	var tests = []struct {
+		name     string
+		plugin   *Timestamp
+		tags     map[string]string
+		fields   map[string]interface{}
+		time     time.Time
+		expected time.Time
+	}{
+		{
+			name:     "unchanged",
+			plugin:   &Timestamp{},
+			time:     time.Unix(100, 123),
+			expected: time.Unix(100, 123),
+		},
+		{
+			name:     "arrival",
+			plugin:   &Timestamp{Source: "arrival"},
+			time:     time.Unix(100, 0),
+			expected: arrival,
+		},
+		{
+			name:     "offset",
+			plugin:   &Timestamp{Offset: internal.Duration{Duration: -time.Hour}},
+			time:     time.Unix(7200, 0),
+			expected: time.Unix(3600, 0),
+		},
+		{
+			name: "truncate",
+			plugin: &Timestamp{
+				Alignment:         "truncate",
+				AlignmentInterval: internal.Duration{Duration: 10 * time.Second},
+			},
+			time:     time.Unix(109, 999999999),
+			expected: time.Unix(100, 0),
+		},
+		{
+			name: "round",
+			plugin: &Timestamp{
+				Alignment:         "round",
+				AlignmentInterval: internal.Duration{Duration: 10 * time.Second},
+			},
+			time:     time.Unix(105, 0),
+			expected: time.Unix(110, 0),
+		},
+		{
+			name: "round down",
+			plugin: &Timestamp{
+				Alignment:         "round",
+				AlignmentInterval: internal.Duration{Duration: 10 * time.Second},
+			},
+			time:     time.Unix(104, 999999999),
+			expected: time.Unix(100, 0),
+		},
+		{
+			name: "truncate before epoch",
+			plugin: &Timestamp{
+				Alignment:         "truncate",
+				AlignmentInterval: internal.Duration{Duration: 10 * time.Second},
+			},
+			time:     time.Unix(-5, 0),
+			expected: time.Unix(-10, 0),
+		},
+		{
+			name: "offset before alignment",
+			plugin: &Timestamp{
+				Offset:            internal.Duration{Duration: 3 * time.Second},
+				Alignment:         "truncate",
+				AlignmentInterval: internal.Duration{Duration: 10 * time.Second},
+			},
+			time:     time.Unix(108, 0),
+			expected: time.Unix(110, 0),
+		},
+		{
+			name:     "field unix",
+			plugin:   &Timestamp{Source: "field", SourceKey: "ts", SourceFormat: "unix"},
+			fields:   map[string]interface{}{"ts": 1234.5},
+			time:     time.Unix(100, 0),
+			expected: time.Unix(1234, 500000000),
+		},
+		{
+			name:     "field unix_ms",
+			plugin:   &Timestamp{Source: "field", SourceKey: "ts", SourceFormat: "unix_ms"},
+			fields:   map[string]interface{}{"ts": uint64(1234567)},
+			time:     time.Unix(100, 0),
+			expected: time.Unix(1234, 567000000),
+		},
+		{
+			name: "tag layout",
+			plugin: &Timestamp{
+				Source:       "tag",
+				SourceKey:    "ts",
+				SourceFormat: "2006-01-02 15:04:05",
+			},
+			tags:     map[string]string{"ts": "2019-04-03 10:20:30"},
+			time:     time.Unix(100, 0),
+			expected: time.Date(2019, 4, 3, 10, 20, 30, 0, time.UTC),
+		},
+		{
+			name:     "missing field",
+			plugin:   &Timestamp{Source: "field", SourceKey: "ts", SourceFormat: "unix"},
+			time:     time.Unix(100, 0),
+			expected: time.Unix(100, 0),
+		},
+		{
+			name:     "invalid field",
+			plugin:   &Timestamp{Source: "field", SourceKey: "ts", SourceFormat: "unix"},
+			fields:   map[string]interface{}{"ts": "yesterday"},
+			time:     time.Unix(100, 0),
+			expected: time.Unix(100, 0),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := tt.plugin
+			plugin.now = func() time.Time { return arrival }
+			require.NoError(t, plugin.Init())
+
+			fields := map[string]interface{}{"value": 42}
+			for k, v := range tt.fields {
+				fields[k] = v
+			}
+			m := testutil.MustMetric("cpu", tt.tags, fields, tt.time)
+
+			actual := plugin.Apply(m)
+			require.Len(t, actual, 1)
+			require.True(t, tt.expected.Equal(actual[0].Time()),
+				"expected %v, got %v", tt.expected, actual[0].Time())
+		})
+	}
+}
+
+func TestApplyAll(t *testing.T) {
+	plugin := New()
+	plugin.Alignment = "truncate"
+	plugin.AlignmentInterval.Duration = time.Minute
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(61, 0)),
+		testutil.MustMetric("mem", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(119, 0)),
+	}
+	expected := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(60, 0)),
+		testutil.MustMetric("mem", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(60, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+}
+
+func TestInit(t *testing.T) {
+	var tests = []struct {
+		name   string
+		plugin *Timestamp
+	}{
+		{
+			name:   "unknown source",
+			plugin: &Timestamp{Source: "clock"},
+		},
+		{
+			name:   "missing source key",
+			plugin: &Timestamp{Source: "tag", SourceFormat: "unix"},
+		},
+		{
+			name: "invalid timezone",
+			plugin: &Timestamp{
+				Source:         "tag",
+				SourceKey:      "ts",
+				SourceFormat:   "unix",
+				SourceTimezone: "Mars/Olympus_Mons",
+			},
+		},
+		{
+			name:   "unknown alignment",
+			plugin: &Timestamp{Alignment: "floor"},
+		},
+		{
+			name:   "missing alignment interval",
+			plugin: &Timestamp{Alignment: "round"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, tt.plugin.Init())
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package units
+
+// unit converts values to and from the base unit of its dimension, a value in
+// the base unit is value*factor + offset.
+type unit struct {
+	dimension string
+	factor    float64
+	offset    float64
+}
+
+func (u unit) toBase(v float64) float64 {
+	return v*u.factor + u.offset
+}
+
+func (u unit) fromBase(v float64) float64 {
+	return (v - u.offset) / u.factor
+}
+
+const (
+	dimensionData        = "data"
+	dimensionTime        = "time"
+	dimensionTemperature = "temperature"
+	dimensionRatio       = "ratio"
+)
+
+// registry contains the known units by name, the base units are bytes,
+// seconds, kelvin and ratio.
+var registry = map[string]unit{
+	"bit":   {dimension: dimensionData, factor: 1.0 / 8},
+	"bits":  {dimension: dimensionData, factor: 1.0 / 8},
+	"kbit":  {dimension: dimensionData, factor: 1e3 / 8},
+	"Mbit":  {dimension: dimensionData, factor: 1e6 / 8},
+	"Gbit":  {dimension: dimensionData, factor: 1e9 / 8},
+	"Kibit": {dimension: dimensionData, factor: (1 << 10) / 8},
+	"Mibit": {dimension: dimensionData, factor: (1 << 20) / 8},
+	"Gibit": {dimension: dimensionData, factor: (1 << 30) / 8},
+
+	"B":     {dimension: dimensionData, factor: 1},
+	"byte":  {dimension: dimensionData, factor: 1},
+	"bytes": {dimension: dimensionData, factor: 1},
+	"kB":    {dimension: dimensionData, factor: 1e3},
+	"MB":    {dimension: dimensionData, factor: 1e6},
+	"GB":    {dimension: dimensionData, factor: 1e9},
+	"TB":    {dimension: dimensionData, factor: 1e12},
+	"KiB":   {dimension: dimensionData, factor: 1 << 10},
+	"MiB":   {dimension: dimensionData, factor: 1 << 20},
+	"GiB":   {dimension: dimensionData, factor: 1 << 30},
+	"TiB":   {dimension: dimensionData, factor: 1 << 40},
+
+	"ns":  {dimension: dimensionTime, factor: 1e-9},
+	"us":  {dimension: dimensionTime, factor: 1e-6},
+	"ms":  {dimension: dimensionTime, factor: 1e-3},
+	"s":   {dimension: dimensionTime, factor: 1},
+	"min": {dimension: dimensionTime, factor: 60},
+	"h":   {dimension: dimensionTime, factor: 3600},
+	"d":   {dimension: dimensionTime, factor: 86400},
+
+	"C": {dimension: dimensionTemperature, factor: 1, offset: 273.15},
+	"F": {dimension: dimensionTemperature, factor: 5.0 / 9, offset: 459.67 * 5 / 9},
+	"K": {dimension: dimensionTemperature, factor: 1},
+
+	"percent": {dimension: dimensionRatio, factor: 0.01},
+	"ratio":   {dimension: dimensionRatio, factor: 1},
+}
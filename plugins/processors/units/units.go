@@ -0,0 +1,127 @@
+package units
+
+import (
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+const sampleConfig = `
+  [[processors.units.conversion]]
+    ## Fields to convert, accepts glob patterns.  At least one is required.
+    fields = ["memory_used", "memory_total"]
+
+    ## Unit of the field values and the unit to convert them to.  Converted
+    ## values are always floats.
+    from = "MiB"
+    to = "bytes"
+
+    ## Tag to add the resulting unit to.
+    # unit_tag = "unit"
+`
+
+type Units struct {
+	Conversions []*Conversion `toml:"conversion"`
+}
+
+// Conversion converts the matching fields from one unit to another.
+type Conversion struct {
+	Fields  []string `toml:"fields"`
+	From    string   `toml:"from"`
+	To      string   `toml:"to"`
+	UnitTag string   `toml:"unit_tag"`
+
+	fieldFilter filter.Filter
+	from        unit
+	to          unit
+}
+
+func (u *Units) SampleConfig() string {
+	return sampleConfig
+}
+
+func (u *Units) Description() string {
+	return "Convert fields between units."
+}
+
+func (u *Units) Init() error {
+	for _, c := range u.Conversions {
+		if len(c.Fields) == 0 {
+			return fmt.Errorf("conversion from %q to %q has no fields", c.From, c.To)
+		}
+
+		var ok bool
+		c.from, ok = registry[c.From]
+		if !ok {
+			return fmt.Errorf("unknown unit %q", c.From)
+		}
+		c.to, ok = registry[c.To]
+		if !ok {
+			return fmt.Errorf("unknown unit %q", c.To)
+		}
+		if c.from.dimension != c.to.dimension {
+			return fmt.Errorf("cannot convert %s %s to %s %s",
+				c.from.dimension, c.From, c.to.dimension, c.To)
+		}
+
+		var err error
+		c.fieldFilter, err = filter.Compile(c.Fields)
+		if err != nil {
+			return fmt.Errorf("could not compile fields: %v", err)
+		}
+	}
+	return nil
+}
+
+func (u *Units) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range in {
+		for _, c := range u.Conversions {
+			c.apply(m)
+		}
+	}
+	return in
+}
+
+func (c *Conversion) apply(m telegraf.Metric) {
+	if c.fieldFilter == nil {
+		return
+	}
+
+	converted := false
+	for _, field := range m.FieldList() {
+		if !c.fieldFilter.Match(field.Key) {
+			continue
+		}
+
+		v, ok := toFloat(field.Value)
+		if !ok {
+			continue
+		}
+		m.AddField(field.Key, c.to.fromBase(c.from.toBase(v)))
+		converted = true
+	}
+
+	if converted && c.UnitTag != "" {
+		m.AddTag(c.UnitTag, c.To)
+	}
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func init() {
+	processors.Add("units", func() telegraf.Processor {
+		return &Units{}
+	})
+}
@@ -0,0 +1,133 @@
+package units
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	var tests = []struct {
+		from     string
+		to       string
+		value    interface{}
+		expected float64
+	}{
+		{from: "MiB", to: "bytes", value: int64(2), expected: 2097152},
+		{from: "bytes", to: "KiB", value: uint64(2048), expected: 2},
+		{from: "kB", to: "B", value: 1.5, expected: 1500},
+		{from: "bytes", to: "bits", value: int64(10), expected: 80},
+		{from: "Mbit", to: "MB", value: int64(8), expected: 1},
+		{from: "ms", to: "s", value: int64(1500), expected: 1.5},
+		{from: "ns", to: "ms", value: int64(2000000), expected: 2},
+		{from: "h", to: "min", value: int64(2), expected: 120},
+		{from: "C", to: "F", value: 100.0, expected: 212},
+		{from: "F", to: "C", value: 32.0, expected: 0},
+		{from: "C", to: "K", value: int64(-273), expected: 0.15},
+		{from: "K", to: "F", value: 0.0, expected: -459.67},
+		{from: "percent", to: "ratio", value: 42.0, expected: 0.42},
+		{from: "ratio", to: "percent", value: 0.5, expected: 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			plugin := &Units{
+				Conversions: []*Conversion{
+					{Fields: []string{"value"}, From: tt.from, To: tt.to},
+				},
+			}
+			require.NoError(t, plugin.Init())
+
+			m := testutil.MustMetric("test", map[string]string{},
+				map[string]interface{}{"value": tt.value}, time.Unix(0, 0))
+			plugin.Apply(m)
+
+			value, ok := m.GetField("value")
+			require.True(t, ok)
+			require.InDelta(t, tt.expected, value, 1e-9)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	plugin := &Units{
+		Conversions: []*Conversion{
+			{
+				Fields:  []string{"memory_*"},
+				From:    "MiB",
+				To:      "bytes",
+				UnitTag: "unit",
+			},
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric(
+			"nvidia_smi",
+			map[string]string{"index": "0"},
+			map[string]interface{}{
+				"memory_used":  int64(1),
+				"memory_total": int64(4),
+				"memory_name":  "gddr5",
+				"temperature":  int64(40),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{"usage": 42.0},
+			time.Unix(0, 0),
+		),
+	}
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"nvidia_smi",
+			map[string]string{"index": "0", "unit": "bytes"},
+			map[string]interface{}{
+				"memory_used":  1048576.0,
+				"memory_total": 4194304.0,
+				"memory_name":  "gddr5",
+				"temperature":  int64(40),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{"usage": 42.0},
+			time.Unix(0, 0),
+		),
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestInit(t *testing.T) {
+	var tests = []struct {
+		name   string
+		fields []string
+		from   string
+		to     string
+	}{
+		{name: "unknown from", fields: []string{"*"}, from: "furlong", to: "bytes"},
+		{name: "unknown to", fields: []string{"*"}, from: "bytes", to: "furlong"},
+		{name: "different dimensions", fields: []string{"*"}, from: "bytes", to: "s"},
+		{name: "no fields", from: "MiB", to: "bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Units{
+				Conversions: []*Conversion{
+					{Fields: tt.fields, From: tt.from, To: tt.to},
+				},
+			}
+			require.Error(t, plugin.Init())
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+const (
+	timestampColumn   = "timestamp"
+	measurementColumn = "measurement"
+)
+
+// Serializer writes metrics as rows of delimiter separated values.
+//
+// If no columns are configured they are selected from the metrics of the
+// first call to Serialize or SerializeBatch: the timestamp, the measurement,
+// the sorted tag keys and the sorted field keys.  The columns then stay the
+// same for the lifetime of the serializer so that every row has the same
+// layout.  When the header is enabled it is written before the first row.
+type Serializer struct {
+	Columns         []string
+	Delimiter       rune
+	Header          bool
+	TimestampFormat string
+
+	// columns holds the columns of all rows once the first row is written.
+	columns []string
+}
+
+func NewSerializer(columns []string, delimiter string, header bool, timestampFormat string) (*Serializer, error) {
+	s := &Serializer{
+		Columns:         columns,
+		Delimiter:       ',',
+		Header:          header,
+		TimestampFormat: timestampFormat,
+	}
+
+	if delimiter != "" {
+		runes := []rune(delimiter)
+		if len(runes) > 1 {
+			return nil, fmt.Errorf("csv_delimiter must be a single character, got: %s", delimiter)
+		}
+		s.Delimiter = runes[0]
+	}
+
+	if s.TimestampFormat == "" {
+		s.TimestampFormat = "unix"
+	}
+	return s, nil
+}
+
+func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	return s.SerializeBatch([]telegraf.Metric{metric})
+}
+
+func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	columns := s.columns
+	if columns == nil {
+		columns = s.Columns
+		if len(columns) == 0 {
+			columns = metricColumns(metrics)
+		}
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = s.Delimiter
+
+	writeHeader := s.Header && s.columns == nil
+	if writeHeader {
+		if err := w.Write(columns); err != nil {
+			return nil, err
+		}
+	}
+
+	row := make([]string, len(columns))
+	for _, metric := range metrics {
+		for i, column := range columns {
+			row[i] = s.value(metric, column)
+		}
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+
+	s.columns = columns
+	return buf.Bytes(), nil
+}
+
+// value returns the value of the column for the metric, tags take precedence
+// over fields with the same key.
+func (s *Serializer) value(metric telegraf.Metric, column string) string {
+	switch column {
+	case timestampColumn:
+		return formatTimestamp(metric.Time(), s.TimestampFormat)
+	case measurementColumn:
+		return metric.Name()
+	}
+
+	if value, ok := metric.GetTag(column); ok {
+		return value
+	}
+
+	if value, ok := metric.GetField(column); ok {
+		return formatValue(value)
+	}
+	return ""
+}
+
+// metricColumns returns the timestamp and measurement columns followed by the
+// sorted tag and field keys of the metrics.
+func metricColumns(metrics []telegraf.Metric) []string {
+	tags := make(map[string]bool)
+	fields := make(map[string]bool)
+	for _, metric := range metrics {
+		for _, tag := range metric.TagList() {
+			tags[tag.Key] = true
+		}
+		for _, field := range metric.FieldList() {
+			if !tags[field.Key] {
+				fields[field.Key] = true
+			}
+		}
+	}
+
+	columns := []string{timestampColumn, measurementColumn}
+	columns = append(columns, sortedKeys(tags)...)
+	return append(columns, sortedKeys(fields)...)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		if k == timestampColumn || k == measurementColumn {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatTimestamp(t time.Time, format string) string {
+	switch format {
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "unix_ms":
+		return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+	case "unix_us":
+		return strconv.FormatInt(t.UnixNano()/int64(time.Microsecond), 10)
+	case "unix_ns":
+		return strconv.FormatInt(t.UnixNano(), 10)
+	default:
+		return t.UTC().Format(format)
+	}
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
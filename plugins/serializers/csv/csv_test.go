@@ -0,0 +1,157 @@
+package csv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerializeBatch(t *testing.T) {
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{
+				"host": "localhost",
+				"cpu":  "cpu0",
+			},
+			map[string]interface{}{
+				"usage_idle": 42.5,
+				"count":      int64(2),
+			},
+			time.Unix(1555000000, 0),
+		),
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{
+				"host": "localhost",
+			},
+			map[string]interface{}{
+				"usage_idle": 43.0,
+				"message":    "a, \"quoted\" value",
+				"ok":         true,
+				"total":      uint64(5),
+			},
+			time.Unix(1555000001, 0),
+		),
+	}
+
+	var tests = []struct {
+		name            string
+		columns         []string
+		delimiter       string
+		header          bool
+		timestampFormat string
+		expected        string
+	}{
+		{
+			name:   "sorted columns",
+			header: true,
+			expected: "timestamp,measurement,cpu,host,count,message,ok,total,usage_idle\n" +
+				"1555000000,cpu,cpu0,localhost,2,,,,42.5\n" +
+				"1555000001,cpu,,localhost,,\"a, \"\"quoted\"\" value\",true,5,43\n",
+		},
+		{
+			name:    "explicit columns",
+			columns: []string{"host", "usage_idle", "timestamp", "missing"},
+			expected: "localhost,42.5,1555000000,\n" +
+				"localhost,43,1555000001,\n",
+		},
+		{
+			name:            "delimiter and timestamp format",
+			columns:         []string{"timestamp", "measurement", "usage_idle"},
+			delimiter:       ";",
+			header:          true,
+			timestampFormat: "2006-01-02T15:04:05Z07:00",
+			expected: "timestamp;measurement;usage_idle\n" +
+				"2019-04-11T16:26:40Z;cpu;42.5\n" +
+				"2019-04-11T16:26:41Z;cpu;43\n",
+		},
+		{
+			name:            "unix milliseconds",
+			columns:         []string{"timestamp"},
+			timestampFormat: "unix_ms",
+			expected:        "1555000000000\n1555000001000\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSerializer(tt.columns, tt.delimiter, tt.header, tt.timestampFormat)
+			require.NoError(t, err)
+			actual, err := s.SerializeBatch(metrics)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, string(actual))
+		})
+	}
+}
+
+func TestColumnsKeptAcrossWrites(t *testing.T) {
+	s, err := NewSerializer(nil, "", true, "")
+	require.NoError(t, err)
+
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{
+			"value": 42.0,
+		},
+		time.Unix(0, 0),
+	)
+	actual, err := s.Serialize(m)
+	require.NoError(t, err)
+	require.Equal(t, "timestamp,measurement,value\n0,cpu,42\n", string(actual))
+
+	// no header for later rows
+	m = testutil.MustMetric(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{
+			"value": 43.0,
+		},
+		time.Unix(1, 0),
+	)
+	actual, err = s.Serialize(m)
+	require.NoError(t, err)
+	require.Equal(t, "1,cpu,43\n", string(actual))
+
+	// other keys keep the columns of the first row
+	m = testutil.MustMetric(
+		"mem",
+		map[string]string{"host": "a"},
+		map[string]interface{}{
+			"used": 44.0,
+		},
+		time.Unix(2, 0),
+	)
+	actual, err = s.Serialize(m)
+	require.NoError(t, err)
+	require.Equal(t, "2,mem,\n", string(actual))
+	require.Empty(t, s.Columns)
+}
+
+func TestHeaderWrittenOnceWithColumns(t *testing.T) {
+	s, err := NewSerializer([]string{"timestamp", "value"}, "", true, "")
+	require.NoError(t, err)
+
+	for i, expected := range []string{"timestamp,value\n0,42\n", "1,42\n"} {
+		m := testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{
+				"value": 42.0,
+				"other": 43.0,
+			},
+			time.Unix(int64(i), 0),
+		)
+		actual, err := s.Serialize(m)
+		require.NoError(t, err)
+		require.Equal(t, expected, string(actual))
+	}
+}
+
+func TestInvalidDelimiter(t *testing.T) {
+	_, err := NewSerializer(nil, "ab", false, "")
+	require.Error(t, err)
+}
@@ -0,0 +1,225 @@
+package msgpack
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// MessagePack format bytes used by the serializer.  Only the subset of the
+// specification needed to represent a metric is written.
+const (
+	formatNil     = 0xc0
+	formatFalse   = 0xc2
+	formatTrue    = 0xc3
+	formatFloat64 = 0xcb
+	formatUint8   = 0xcc
+	formatUint16  = 0xcd
+	formatUint32  = 0xce
+	formatUint64  = 0xcf
+	formatInt8    = 0xd0
+	formatInt16   = 0xd1
+	formatInt32   = 0xd2
+	formatInt64   = 0xd3
+	formatFixExt4 = 0xd6
+	formatFixExt8 = 0xd7
+	formatExt8    = 0xc7
+	formatStr8    = 0xd9
+	formatStr16   = 0xda
+	formatStr32   = 0xdb
+	formatMap16   = 0xde
+	formatMap32   = 0xdf
+
+	// extTimestamp is the extension type -1 reserved for timestamps.
+	extTimestamp = 0xff
+)
+
+// Serializer encodes each metric as a MessagePack map with the keys "name",
+// "time", "tags" and "fields".  Integer fields are always written with a
+// signed format and unsigned fields with an unsigned format so that the
+// original field type can be recovered by the parser.
+type Serializer struct {
+}
+
+func NewSerializer() (*Serializer, error) {
+	return &Serializer{}, nil
+}
+
+func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	return appendMetric(nil, metric)
+}
+
+func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	var buf []byte
+	var err error
+	for _, metric := range metrics {
+		buf, err = appendMetric(buf, metric)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+func appendMetric(buf []byte, metric telegraf.Metric) ([]byte, error) {
+	buf = appendMapHeader(buf, 4)
+
+	buf = appendString(buf, "name")
+	buf = appendString(buf, metric.Name())
+
+	buf = appendString(buf, "time")
+	buf = appendTime(buf, metric.Time())
+
+	buf = appendString(buf, "tags")
+	buf = appendMapHeader(buf, len(metric.TagList()))
+	for _, tag := range metric.TagList() {
+		buf = appendString(buf, tag.Key)
+		buf = appendString(buf, tag.Value)
+	}
+
+	buf = appendString(buf, "fields")
+	buf = appendMapHeader(buf, len(metric.FieldList()))
+	for _, field := range metric.FieldList() {
+		buf = appendString(buf, field.Key)
+
+		var err error
+		buf, err = appendValue(buf, field.Value)
+		if err != nil {
+			return nil, fmt.Errorf("field %q of metric %q: %v", field.Key, metric.Name(), err)
+		}
+	}
+	return buf, nil
+}
+
+func appendValue(buf []byte, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case int64:
+		return appendInt(buf, v), nil
+	case uint64:
+		return appendUint(buf, v), nil
+	case float64:
+		buf = append(buf, formatFloat64)
+		return appendUint64(buf, math.Float64bits(v)), nil
+	case string:
+		return appendString(buf, v), nil
+	case bool:
+		if v {
+			return append(buf, formatTrue), nil
+		}
+		return append(buf, formatFalse), nil
+	case nil:
+		return append(buf, formatNil), nil
+	default:
+		return nil, fmt.Errorf("unsupported type %T", value)
+	}
+}
+
+func appendMapHeader(buf []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(buf, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		buf = append(buf, formatMap16)
+		return appendUint16(buf, uint16(n))
+	default:
+		buf = append(buf, formatMap32)
+		return appendUint32(buf, uint32(n))
+	}
+}
+
+func appendString(buf []byte, s string) []byte {
+	n := len(s)
+	switch {
+	case n < 32:
+		buf = append(buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		buf = append(buf, formatStr8, byte(n))
+	case n <= math.MaxUint16:
+		buf = append(buf, formatStr16)
+		buf = appendUint16(buf, uint16(n))
+	default:
+		buf = append(buf, formatStr32)
+		buf = appendUint32(buf, uint32(n))
+	}
+	return append(buf, s...)
+}
+
+// appendInt writes v using the smallest format that is decoded as a signed
+// integer.  Positive fixints are treated as signed by the parser.
+func appendInt(buf []byte, v int64) []byte {
+	switch {
+	case v >= -32 && v <= math.MaxInt8:
+		return append(buf, byte(v))
+	case v >= math.MinInt8 && v <= math.MaxInt8:
+		return append(buf, formatInt8, byte(v))
+	case v >= math.MinInt16 && v <= math.MaxInt16:
+		buf = append(buf, formatInt16)
+		return appendUint16(buf, uint16(v))
+	case v >= math.MinInt32 && v <= math.MaxInt32:
+		buf = append(buf, formatInt32)
+		return appendUint32(buf, uint32(v))
+	default:
+		buf = append(buf, formatInt64)
+		return appendUint64(buf, uint64(v))
+	}
+}
+
+// appendUint writes v using the smallest unsigned integer format; fixints
+// are never used so the value is not mistaken for a signed integer.
+func appendUint(buf []byte, v uint64) []byte {
+	switch {
+	case v <= math.MaxUint8:
+		return append(buf, formatUint8, byte(v))
+	case v <= math.MaxUint16:
+		buf = append(buf, formatUint16)
+		return appendUint16(buf, uint16(v))
+	case v <= math.MaxUint32:
+		buf = append(buf, formatUint32)
+		return appendUint32(buf, uint32(v))
+	default:
+		buf = append(buf, formatUint64)
+		return appendUint64(buf, v)
+	}
+}
+
+// appendTime writes t using the timestamp extension type in the most compact
+// of the 32, 64 or 96 bit representations.
+func appendTime(buf []byte, t time.Time) []byte {
+	sec := t.Unix()
+	nsec := int64(t.Nanosecond())
+
+	if sec>>34 == 0 {
+		data := uint64(nsec)<<34 | uint64(sec)
+		if data&0xffffffff00000000 == 0 {
+			buf = append(buf, formatFixExt4, extTimestamp)
+			return appendUint32(buf, uint32(data))
+		}
+		buf = append(buf, formatFixExt8, extTimestamp)
+		return appendUint64(buf, data)
+	}
+
+	buf = append(buf, formatExt8, 12, extTimestamp)
+	buf = appendUint32(buf, uint32(nsec))
+	return appendUint64(buf, uint64(sec))
+}
+
+func appendUint16(buf []byte, v uint16) []byte {
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], v)
+	return append(buf, b[:]...)
+}
+
+func appendUint32(buf []byte, v uint32) []byte {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	return append(buf, b[:]...)
+}
+
+func appendUint64(buf []byte, v uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	return append(buf, b[:]...)
+}
@@ -0,0 +1,156 @@
+package msgpack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerializeMetric(t *testing.T) {
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{
+			"host": "a",
+		},
+		map[string]interface{}{
+			"value": int64(1),
+		},
+		time.Unix(1, 0),
+	)
+
+	s, err := NewSerializer()
+	require.NoError(t, err)
+
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	expected := []byte{
+		0x84,
+		0xa4, 'n', 'a', 'm', 'e', 0xa3, 'c', 'p', 'u',
+		0xa4, 't', 'i', 'm', 'e', 0xd6, 0xff, 0x00, 0x00, 0x00, 0x01,
+		0xa4, 't', 'a', 'g', 's', 0x81, 0xa4, 'h', 'o', 's', 't', 0xa1, 'a',
+		0xa6, 'f', 'i', 'e', 'l', 'd', 's', 0x81, 0xa5, 'v', 'a', 'l', 'u', 'e', 0x01,
+	}
+	require.Equal(t, expected, buf)
+}
+
+func TestSerializeFieldTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected []byte
+	}{
+		{
+			name:     "positive fixint",
+			value:    int64(127),
+			expected: []byte{0x7f},
+		},
+		{
+			name:     "negative fixint",
+			value:    int64(-32),
+			expected: []byte{0xe0},
+		},
+		{
+			name:     "int8",
+			value:    int64(-33),
+			expected: []byte{0xd0, 0xdf},
+		},
+		{
+			name:     "int16",
+			value:    int64(128),
+			expected: []byte{0xd1, 0x00, 0x80},
+		},
+		{
+			name:     "int64",
+			value:    int64(-1 << 40),
+			expected: []byte{0xd3, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x00, 0x00},
+		},
+		{
+			name:     "small uint is not a fixint",
+			value:    uint64(1),
+			expected: []byte{0xcc, 0x01},
+		},
+		{
+			name:     "uint32",
+			value:    uint64(1 << 16),
+			expected: []byte{0xce, 0x00, 0x01, 0x00, 0x00},
+		},
+		{
+			name:     "float",
+			value:    float64(1),
+			expected: []byte{0xcb, 0x3f, 0xf0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		},
+		{
+			name:     "string",
+			value:    "ok",
+			expected: []byte{0xa2, 'o', 'k'},
+		},
+		{
+			name:     "bool",
+			value:    true,
+			expected: []byte{0xc3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf, err := appendValue(nil, tt.value)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, buf)
+		})
+	}
+}
+
+func TestSerializeTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		time     time.Time
+		expected []byte
+	}{
+		{
+			name:     "timestamp 32",
+			time:     time.Unix(0x01020304, 0),
+			expected: []byte{0xd6, 0xff, 0x01, 0x02, 0x03, 0x04},
+		},
+		{
+			name:     "timestamp 64",
+			time:     time.Unix(1, 1),
+			expected: []byte{0xd7, 0xff, 0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, 0x01},
+		},
+		{
+			name: "timestamp 96",
+			time: time.Unix(-1, 0),
+			expected: []byte{0xc7, 0x0c, 0xff,
+				0x00, 0x00, 0x00, 0x00,
+				0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, appendTime(nil, tt.time))
+		})
+	}
+}
+
+func TestSerializeBatch(t *testing.T) {
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{
+			"value": 42.0,
+		},
+		time.Unix(0, 0),
+	)
+
+	s, err := NewSerializer()
+	require.NoError(t, err)
+
+	single, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	batch, err := s.SerializeBatch([]telegraf.Metric{m, m})
+	require.NoError(t, err)
+	require.Equal(t, append(single, single...), batch)
+}
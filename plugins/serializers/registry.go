@@ -6,11 +6,14 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/serializers/carbon2"
+	"github.com/influxdata/telegraf/plugins/serializers/csv"
 	"github.com/influxdata/telegraf/plugins/serializers/graphite"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/plugins/serializers/json"
+	"github.com/influxdata/telegraf/plugins/serializers/msgpack"
 	"github.com/influxdata/telegraf/plugins/serializers/nowmetric"
 	"github.com/influxdata/telegraf/plugins/serializers/splunkmetric"
+	"github.com/influxdata/telegraf/plugins/serializers/template"
 	"github.com/influxdata/telegraf/plugins/serializers/wavefront"
 )
 
@@ -79,6 +82,24 @@ type Config struct {
 	// Use Strict rules to sanitize metric and tag names from invalid characters for Wavefront
 	// When enabled forward slash (/) and comma (,) will be accepted
 	WavefrontUseStrict bool
+
+	// Columns to write in order; csv format only
+	CSVColumns []string
+
+	// Column separator; csv format only
+	CSVDelimiter string
+
+	// Write a header row with the column names; csv format only
+	CSVHeader bool
+
+	// Format of the timestamp column; csv format only
+	CSVTimestampFormat string
+
+	// Go text/template used to render each metric; template format only
+	TemplateFormat string
+
+	// Go text/template used to render a batch of metrics; template format only
+	TemplateBatchFormat string
 }
 
 // NewSerializer a Serializer interface based on the given config.
@@ -100,6 +121,12 @@ func NewSerializer(config *Config) (Serializer, error) {
 		serializer, err = NewCarbon2Serializer()
 	case "wavefront":
 		serializer, err = NewWavefrontSerializer(config.Prefix, config.WavefrontUseStrict, config.WavefrontSourceOverride)
+	case "msgpack":
+		serializer, err = NewMsgpackSerializer()
+	case "csv":
+		serializer, err = NewCSVSerializer(config.CSVColumns, config.CSVDelimiter, config.CSVHeader, config.CSVTimestampFormat)
+	case "template":
+		serializer, err = NewTemplateSerializer(config.TemplateFormat, config.TemplateBatchFormat)
 	default:
 		err = fmt.Errorf("Invalid data format: %s", config.DataFormat)
 	}
@@ -122,6 +149,18 @@ func NewSplunkmetricSerializer(splunkmetric_hec_routing bool) (Serializer, error
 	return splunkmetric.NewSerializer(splunkmetric_hec_routing)
 }
 
+func NewMsgpackSerializer() (Serializer, error) {
+	return msgpack.NewSerializer()
+}
+
+func NewCSVSerializer(columns []string, delimiter string, header bool, timestampFormat string) (Serializer, error) {
+	return csv.NewSerializer(columns, delimiter, header, timestampFormat)
+}
+
+func NewTemplateSerializer(format, batchFormat string) (Serializer, error) {
+	return template.NewSerializer(format, batchFormat)
+}
+
 func NewNowSerializer() (Serializer, error) {
 	return nowmetric.NewSerializer()
 }
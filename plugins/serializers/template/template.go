@@ -0,0 +1,150 @@
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Metric is the value passed to the templates, it adds accessors to
+// telegraf.Metric that can be called from a template.
+type Metric struct {
+	telegraf.Metric
+}
+
+// Tag returns the value of the tag or an empty string if it does not exist.
+func (m Metric) Tag(key string) string {
+	value, _ := m.GetTag(key)
+	return value
+}
+
+// Field returns the value of the field or nil if it does not exist.
+func (m Metric) Field(key string) interface{} {
+	value, _ := m.GetField(key)
+	return value
+}
+
+var funcs = template.FuncMap{
+	"unix":       unix,
+	"timeFormat": timeFormat,
+	"quote":      strconv.Quote,
+	"json":       toJSON,
+	"replace":    replace,
+	"join":       strings.Join,
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+}
+
+// unix returns the time since the epoch in the given units, such as "1s" or
+// "1ms".
+func unix(units string, t time.Time) (int64, error) {
+	d, err := time.ParseDuration(units)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid units %q", units)
+	}
+	return t.UnixNano() / int64(d), nil
+}
+
+// timeFormat formats the time using a Go reference time layout.
+func timeFormat(layout string, t time.Time) string {
+	return t.Format(layout)
+}
+
+func toJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// replace replaces each of the characters in chars found in s with the
+// replacement string.
+func replace(chars, replacement string, s string) string {
+	var oldnew []string
+	for _, r := range chars {
+		oldnew = append(oldnew, string(r), replacement)
+	}
+	return strings.NewReplacer(oldnew...).Replace(s)
+}
+
+// Serializer renders metrics using Go text/template templates.
+type Serializer struct {
+	metric *template.Template
+	batch  *template.Template
+}
+
+// NewSerializer returns a Serializer that renders each metric with the metric
+// template.  If batch is not empty it is used to render a batch of metrics,
+// otherwise a batch is rendered as the concatenation of each metric.  The
+// batch template can render a single metric with {{template "metric" .}}.
+func NewSerializer(metric, batch string) (*Serializer, error) {
+	if metric == "" {
+		return nil, fmt.Errorf("template serializer requires a metric template")
+	}
+
+	s := &Serializer{}
+
+	var err error
+	s.metric, err = template.New("metric").Funcs(funcs).Parse(metric)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metric template: %v", err)
+	}
+
+	if batch != "" {
+		s.batch, err = template.Must(s.metric.Clone()).New("batch").Parse(batch)
+		if err != nil {
+			return nil, fmt.Errorf("invalid batch template: %v", err)
+		}
+	}
+	return s, nil
+}
+
+// Serialize renders a single metric, a newline is added if the output does
+// not end with one.
+func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := s.metric.Execute(&buf, Metric{metric}); err != nil {
+		return nil, err
+	}
+
+	out := buf.Bytes()
+	if len(out) > 0 && out[len(out)-1] != '\n' {
+		out = append(out, '\n')
+	}
+	return out, nil
+}
+
+func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	if s.batch == nil {
+		var out []byte
+		for _, metric := range metrics {
+			buf, err := s.Serialize(metric)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, buf...)
+		}
+		return out, nil
+	}
+
+	batch := make([]Metric, 0, len(metrics))
+	for _, metric := range metrics {
+		batch = append(batch, Metric{metric})
+	}
+
+	var buf bytes.Buffer
+	if err := s.batch.Execute(&buf, batch); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
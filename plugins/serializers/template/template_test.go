@@ -0,0 +1,150 @@
+package template
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerialize(t *testing.T) {
+	var tests = []struct {
+		name     string
+		format   string
+		metric   telegraf.Metric
+		expected string
+	}{
+		{
+			name:   "name tags and fields",
+			format: `{{.Name}}{{range $k, $v := .Tags}} {{$k}}={{$v}}{{end}}{{range $k, $v := .Fields}} {{$k}}={{$v}}{{end}} {{unix "1s" .Time}}`,
+			metric: testutil.MustMetric(
+				"cpu",
+				map[string]string{
+					"host": "localhost",
+					"cpu":  "cpu0",
+				},
+				map[string]interface{}{
+					"usage_idle": 42.5,
+					"count":      int64(2),
+				},
+				time.Unix(1555000000, 0),
+			),
+			expected: "cpu cpu=cpu0 host=localhost count=2 usage_idle=42.5 1555000000\n",
+		},
+		{
+			name:   "tag and field accessors",
+			format: `{{.Tag "host"}} {{.Field "value"}} {{.Tag "missing"}}{{.Field "missing"}}` + "\n",
+			metric: testutil.MustMetric(
+				"cpu",
+				map[string]string{
+					"host": "localhost",
+				},
+				map[string]interface{}{
+					"value": 42.0,
+				},
+				time.Unix(0, 0),
+			),
+			expected: "localhost 42 <no value>\n",
+		},
+		{
+			name:   "time formatting",
+			format: `{{unix "1ms" .Time}} {{timeFormat "2006-01-02T15:04:05Z07:00" .Time.UTC}}`,
+			metric: testutil.MustMetric(
+				"cpu",
+				map[string]string{},
+				map[string]interface{}{
+					"value": 42.0,
+				},
+				time.Unix(1555000000, 123456789),
+			),
+			expected: "1555000000123 2019-04-11T16:26:40Z\n",
+		},
+		{
+			name:   "escaping",
+			format: `{{replace " ," "_" .Name}} {{quote (.Tag "path")}} {{json .Fields}} {{upper .Name}}`,
+			metric: testutil.MustMetric(
+				"cpu usage,total",
+				map[string]string{
+					"path": `C:\"temp"`,
+				},
+				map[string]interface{}{
+					"value": "a\"b",
+				},
+				time.Unix(0, 0),
+			),
+			expected: `cpu_usage_total "C:\\\"temp\"" {"value":"a\"b"} CPU USAGE,TOTAL` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSerializer(tt.format, "")
+			require.NoError(t, err)
+			actual, err := s.Serialize(tt.metric)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, string(actual))
+		})
+	}
+}
+
+func TestSerializeBatch(t *testing.T) {
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{
+				"value": 42.0,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"mem",
+			map[string]string{},
+			map[string]interface{}{
+				"value": 43.0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	s, err := NewSerializer(`{{.Name}}={{.Field "value"}}`, "")
+	require.NoError(t, err)
+	actual, err := s.SerializeBatch(metrics)
+	require.NoError(t, err)
+	require.Equal(t, "cpu=42\nmem=43\n", string(actual))
+
+	s, err = NewSerializer(`{{.Name}}={{.Field "value"}}`,
+		`[{{range $i, $m := .}}{{if $i}},{{end}}{{template "metric" $m}}{{end}}]`)
+	require.NoError(t, err)
+	actual, err = s.SerializeBatch(metrics)
+	require.NoError(t, err)
+	require.Equal(t, "[cpu=42,mem=43]", string(actual))
+}
+
+func TestInvalidTemplate(t *testing.T) {
+	_, err := NewSerializer("", "")
+	require.Error(t, err)
+
+	_, err = NewSerializer("{{.Name", "")
+	require.Error(t, err)
+
+	_, err = NewSerializer("{{.Name}}", "{{range}}")
+	require.Error(t, err)
+}
+
+func TestExecuteError(t *testing.T) {
+	s, err := NewSerializer(`{{unix "invalid" .Time}}`, "")
+	require.NoError(t, err)
+
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{
+			"value": 42.0,
+		},
+		time.Unix(0, 0),
+	)
+	_, err = s.Serialize(m)
+	require.Error(t, err)
+}
@@ -10,3 +10,13 @@ type Processor interface {
 	// Apply the filter to the given metric.
 	Apply(in ...Metric) []Metric
 }
+
+// StoppableProcessor is a Processor that needs to release resources, or emit
+// the metrics it is holding, when Telegraf stops.
+type StoppableProcessor interface {
+	Processor
+
+	// Stop is called once after the last call to Apply.  The returned
+	// metrics are passed to the processors following this one.
+	Stop() []Metric
+}